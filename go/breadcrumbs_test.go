@@ -0,0 +1,155 @@
+package errortracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestAddBreadcrumb(t *testing.T) {
+	t.Run("should attach breadcrumbs to events", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.AddBreadcrumb(types.Breadcrumb{Category: "auth", Message: "user logged in"})
+		client.AddBreadcrumb(types.Breadcrumb{Category: "cart", Message: "item added"})
+		client.Error(errors.New("test error"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Breadcrumbs) != 2 {
+			t.Fatalf("expected 2 breadcrumbs, got %d", len(event.Breadcrumbs))
+		}
+		if event.Breadcrumbs[0].Message != "user logged in" {
+			t.Errorf("expected oldest breadcrumb first, got %s", event.Breadcrumbs[0].Message)
+		}
+		if event.Breadcrumbs[0].Timestamp.IsZero() {
+			t.Error("expected timestamp to be filled")
+		}
+		if event.Breadcrumbs[0].Level != types.LevelInfo {
+			t.Errorf("expected default level INFO, got %s", event.Breadcrumbs[0].Level)
+		}
+	})
+
+	t.Run("should keep only the most recent breadcrumbs", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:     "https://api.example.com/webhook",
+			LicenseID:      "test-license",
+			LicenseDevice:  "test-device",
+			Enabled:        true,
+			MaxBreadcrumbs: 3,
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for i := 0; i < 10; i++ {
+			client.AddBreadcrumb(types.Breadcrumb{Message: fmt.Sprintf("crumb %d", i)})
+		}
+		client.Error(errors.New("test error"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Breadcrumbs) != 3 {
+			t.Fatalf("expected 3 breadcrumbs, got %d", len(event.Breadcrumbs))
+		}
+		if event.Breadcrumbs[0].Message != "crumb 7" || event.Breadcrumbs[2].Message != "crumb 9" {
+			t.Errorf("expected crumbs 7..9, got %v", event.Breadcrumbs)
+		}
+	})
+
+	t.Run("should disable breadcrumbs with negative limit", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:     "https://api.example.com/webhook",
+			LicenseID:      "test-license",
+			LicenseDevice:  "test-device",
+			Enabled:        true,
+			MaxBreadcrumbs: -1,
+		}
+
+		client, _ := NewClient(config)
+		client.AddBreadcrumb(types.Breadcrumb{Message: "ignored"})
+		client.Error(errors.New("test error"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Breadcrumbs) != 0 {
+			t.Errorf("expected no breadcrumbs, got %d", len(event.Breadcrumbs))
+		}
+	})
+
+	t.Run("should default max breadcrumbs", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		if client.config.MaxBreadcrumbs != 100 {
+			t.Errorf("expected MaxBreadcrumbs to be 100, got %d", client.config.MaxBreadcrumbs)
+		}
+	})
+
+	t.Run("should merge hub and client breadcrumbs in order", func(t *testing.T) {
+		client := newContextTestClient(t)
+		now := time.Now()
+
+		client.AddBreadcrumb(types.Breadcrumb{Message: "first", Timestamp: now})
+		hub := client.Hub().Clone()
+		hub.AddBreadcrumb(types.Breadcrumb{Message: "second", Timestamp: now.Add(time.Second)})
+		client.AddBreadcrumb(types.Breadcrumb{Message: "root only", Timestamp: now.Add(2 * time.Second)})
+
+		ctx := ContextWithHub(context.Background(), hub)
+		if err := AddBreadcrumbCtx(ctx, types.Breadcrumb{Message: "third", Timestamp: now.Add(3 * time.Second)}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		client.ErrorCtx(ctx, errors.New("test error"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		want := []string{"first", "second", "third"}
+		if len(event.Breadcrumbs) != len(want) {
+			t.Fatalf("expected %d breadcrumbs, got %v", len(want), event.Breadcrumbs)
+		}
+		for i, message := range want {
+			if event.Breadcrumbs[i].Message != message {
+				t.Errorf("expected breadcrumb %d to be %q, got %q", i, message, event.Breadcrumbs[i].Message)
+			}
+		}
+	})
+	t.Run("should keep the newest breadcrumbs of full scopes", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:     "https://api.example.com/webhook",
+			LicenseID:      "test-license",
+			LicenseDevice:  "test-device",
+			Enabled:        true,
+			MaxBreadcrumbs: 3,
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		now := time.Now()
+		for i := 0; i < 3; i++ {
+			client.AddBreadcrumb(types.Breadcrumb{Message: fmt.Sprintf("root %d", i), Timestamp: now.Add(time.Duration(2*i) * time.Second)})
+		}
+		withBreadcrumbs := func(scope *core.Scope) {
+			for i := 0; i < 3; i++ {
+				scope.AddBreadcrumb(types.Breadcrumb{Message: fmt.Sprintf("call %d", i), Timestamp: now.Add(time.Duration(2*i+1) * time.Second)}, 3)
+			}
+		}
+
+		client.ErrorCtx(context.Background(), errors.New("test error"), types.LevelError, nil, withBreadcrumbs)
+
+		event := lastQueuedEvent(t, client)
+		want := []string{"call 1", "root 2", "call 2"}
+		if len(event.Breadcrumbs) != len(want) {
+			t.Fatalf("expected %d breadcrumbs, got %v", len(want), event.Breadcrumbs)
+		}
+		for i, message := range want {
+			if event.Breadcrumbs[i].Message != message {
+				t.Errorf("expected breadcrumb %d to be %q, got %q", i, message, event.Breadcrumbs[i].Message)
+			}
+		}
+	})
+}
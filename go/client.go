@@ -1,7 +1,9 @@
 package errortracker
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -14,20 +16,25 @@ type ErrorTrackerClient struct {
 	config       *types.ClientConfig
 	eventBuilder *core.EventBuilder
 	transport    *core.Transport
+	hub          *Hub
 	eventQueue   []types.EventIssue
 	queueMu      sync.Mutex
 	isActive     bool
 	isEnabled    bool
 	isProcessing bool
 	stopChan     chan struct{}
+	stopOnce     sync.Once
 	wg           sync.WaitGroup
+	processorsMu sync.RWMutex
+	processors   []types.EventProcessor
+	dedupe       *deduper
+	rateLimiter  *rateLimiter
+	scrubber     *core.Scrubber
+	stats        clientStats
+	crashFile    *os.File
 }
 
 func NewClient(config *types.ClientConfig) (*ErrorTrackerClient, error) {
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
-	}
-
 	if config.MaxRetries == 0 {
 		config.MaxRetries = 3
 	}
@@ -40,15 +47,53 @@ func NewClient(config *types.ClientConfig) (*ErrorTrackerClient, error) {
 	if config.MaxQueueSize == 0 {
 		config.MaxQueueSize = 50
 	}
+	if config.MaxBreadcrumbs == 0 {
+		config.MaxBreadcrumbs = 100
+	}
+	if config.MaxErrorDepth == 0 {
+		config.MaxErrorDepth = 10
+	}
+	if config.SampleRate == 0 {
+		config.SampleRate = 1
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	build, modules := detectBuild(config)
+	eventBuilder := core.NewEventBuilder(config.App, config.Version, config.Platform, config.LicenseDevice).
+		SetInAppPrefixes(config.InAppPrefixes...).
+		SetMaxErrorDepth(config.MaxErrorDepth).
+		SetMetadataLimits(config.MetadataLimits).
+		SetMemStatsMaxAge(config.MemStatsMaxAge).
+		SetHost(core.LocalHost()).
+		SetBuild(build, modules).
+		SetSourceContext(config.SourceContextLines, config.SourceCacheBytes).
+		SetGoroutineDump(config.AttachGoroutines, config.MaxGoroutineDumpBytes)
 
 	client := &ErrorTrackerClient{
 		config:       config,
-		eventBuilder: core.NewEventBuilder(config.App, config.Version, config.Platform, config.LicenseDevice),
+		eventBuilder: eventBuilder,
 		transport:    core.NewTransport(config),
 		eventQueue:   make([]types.EventIssue, 0, config.MaxQueueSize),
 		isEnabled:    config.Enabled,
 		stopChan:     make(chan struct{}),
 	}
+	client.hub = NewHub(client, nil)
+	client.rateLimiter = newRateLimiter(config.FingerprintRateLimit, config.LevelRateLimits)
+	client.scrubber = core.NewScrubber(config.Scrubbing)
+	if config.DedupeWindow > 0 {
+		client.dedupe = newDeduper(config.DedupeWindow)
+	}
+	if config.CrashFile != "" {
+		if err := client.reportPreviousCrash(); err != nil {
+			return nil, err
+		}
+		if err := client.openCrashFile(); err != nil {
+			return nil, err
+		}
+	}
 
 	return client, nil
 }
@@ -59,39 +104,92 @@ func (c *ErrorTrackerClient) Start() *ErrorTrackerClient {
 	}
 
 	c.isActive = true
+	c.registerCrashFile()
 	c.startBatchProcessor()
 	return c
 }
 
-func (c *ErrorTrackerClient) Error(err error, level types.EventLevel, metadata map[string]string) *ErrorTrackerClient {
-	if !c.isEnabled {
-		return c
-	}
+func (c *ErrorTrackerClient) Error(err error, level types.EventLevel, metadata map[string]string, options ...CaptureOption) *ErrorTrackerClient {
+	return c.ErrorCtx(context.Background(), err, level, metadata, options...)
+}
 
-	title := "Unknown error"
-	if err != nil {
-		title = err.Error()
-	}
+func (c *ErrorTrackerClient) ErrorCtx(ctx context.Context, err error, level types.EventLevel, metadata map[string]string, options ...CaptureOption) *ErrorTrackerClient {
+	c.captureError(ctx, c.scopeFor(ctx), err, level, stringMetadata(metadata), options)
+	return c
+}
 
-	event := c.eventBuilder.Build(title, err, level, metadata)
-	c.enqueue(event)
+func (c *ErrorTrackerClient) Event(title string, level types.EventLevel, metadata map[string]string, options ...CaptureOption) *ErrorTrackerClient {
+	return c.EventCtx(context.Background(), title, level, metadata, options...)
+}
 
+func (c *ErrorTrackerClient) EventCtx(ctx context.Context, title string, level types.EventLevel, metadata map[string]string, options ...CaptureOption) *ErrorTrackerClient {
+	c.captureEvent(ctx, c.scopeFor(ctx), title, level, stringMetadata(metadata), options)
 	return c
 }
 
-func (c *ErrorTrackerClient) Event(title string, level types.EventLevel, metadata map[string]string) *ErrorTrackerClient {
-	if !c.isEnabled {
-		return c
+// CaptureError reports err with structured metadata. Values may be any Go
+// value; they are made safe to serialize within the configured limits.
+func (c *ErrorTrackerClient) CaptureError(ctx context.Context, err error, level types.EventLevel, data map[string]any, options ...CaptureOption) *ErrorTrackerClient {
+	c.captureError(ctx, c.scopeFor(ctx), err, level, data, options)
+	return c
+}
+
+// CaptureEvent reports a message with structured metadata.
+func (c *ErrorTrackerClient) CaptureEvent(ctx context.Context, title string, level types.EventLevel, data map[string]any, options ...CaptureOption) *ErrorTrackerClient {
+	c.captureEvent(ctx, c.scopeFor(ctx), title, level, data, options)
+	return c
+}
+
+// CaptureCrash reports the panic or fatal error in output, which is what a
+// Go program writes to stderr as it dies, for example a crash log read from
+// disk. It reports false when output holds no crash. The output may come from
+// another program or host, so the event carries the configured app, version
+// and device but not the host, OS, build or modules of this process.
+func (c *ErrorTrackerClient) CaptureCrash(ctx context.Context, output []byte, options ...CaptureOption) bool {
+	ctx = withOptions(ctx, options)
+	scope := c.scopeFor(ctx)
+	event, ok := c.eventBuilder.BuildCrash(ctx, output, scope)
+	if !ok {
+		return false
 	}
+	event.Context.Host = nil
+	event.Context.OS = nil
+	event.Context.Build = nil
+	event.Context.Modules = nil
+	if c.isEnabled {
+		c.send(ctx, scope, event, types.Hint{})
+	}
+	return true
+}
 
-	err := fmt.Errorf("%s", title)
-	event := c.eventBuilder.Build(title, err, level, metadata)
-	c.enqueue(event)
+func (c *ErrorTrackerClient) Hub() *Hub {
+	return c.hub
+}
+
+func (c *ErrorTrackerClient) ConfigureScope(f func(scope *core.Scope)) *ErrorTrackerClient {
+	c.hub.ConfigureScope(f)
+	return c
+}
+
+func (c *ErrorTrackerClient) AddBreadcrumb(breadcrumb types.Breadcrumb) *ErrorTrackerClient {
+	c.hub.AddBreadcrumb(breadcrumb)
+	return c
+}
 
+// SetUser sets the user reported with every event of the client. Request
+// handlers should set it on their hub or context instead.
+func (c *ErrorTrackerClient) SetUser(user types.User) *ErrorTrackerClient {
+	c.hub.SetUser(user)
 	return c
 }
 
+// ForceFlush sends every queued event, including the ones held in the dedupe
+// window.
 func (c *ErrorTrackerClient) ForceFlush() error {
+	if c.dedupe != nil {
+		c.dedupe.flush()
+	}
+
 	for {
 		c.queueMu.Lock()
 		queueLen := len(c.eventQueue)
@@ -122,12 +220,63 @@ func (c *ErrorTrackerClient) Shutdown() error {
 	c.isEnabled = false
 	c.isActive = false
 
-	close(c.stopChan)
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
 	c.wg.Wait()
+	if c.crashFile != nil {
+		c.crashFile.Close()
+		c.crashFile = nil
+	}
 
 	return c.ForceFlush()
 }
 
+func (c *ErrorTrackerClient) captureError(ctx context.Context, scope *core.Scope, err error, level types.EventLevel, metadata map[string]any, options []CaptureOption) {
+	if !c.isEnabled {
+		return
+	}
+
+	title := "Unknown error"
+	if err != nil {
+		title = err.Error()
+	}
+
+	ctx = withOptions(ctx, options)
+	event := c.eventBuilder.Build(ctx, title, err, level, metadata, scope)
+	c.send(ctx, scope, event, types.Hint{OriginalError: err})
+}
+
+func (c *ErrorTrackerClient) captureEvent(ctx context.Context, scope *core.Scope, title string, level types.EventLevel, metadata map[string]any, options []CaptureOption) {
+	if !c.isEnabled {
+		return
+	}
+
+	ctx = withOptions(ctx, options)
+	event := c.eventBuilder.BuildMessage(ctx, title, level, metadata, scope)
+	c.send(ctx, scope, event, types.Hint{})
+}
+
+func stringMetadata(metadata map[string]string) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+	values := make(map[string]any, len(metadata))
+	for key, value := range metadata {
+		values[key] = value
+	}
+	return values
+}
+
+// scopeFor prefers the scope of a hub carried by ctx, so per-request hubs
+// cloned from this client are honoured by the plain capture methods.
+func (c *ErrorTrackerClient) scopeFor(ctx context.Context) *core.Scope {
+	if hub := HubFromContext(ctx); hub != nil && hub.client == c {
+		return hub.Scope()
+	}
+	return c.hub.Scope()
+}
+
 func (c *ErrorTrackerClient) startBatchProcessor() {
 	c.wg.Add(1)
 	go func() {
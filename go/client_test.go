@@ -600,4 +600,26 @@ func TestClientForceFlush(t *testing.T) {
 			t.Errorf("expected no error, got %v", err)
 		}
 	})
+	t.Run("should shutdown twice", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+		}
+
+		client, _ := NewClient(config)
+		client.Start()
+
+		if err := client.Shutdown(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if err := client.Shutdown(); err != nil {
+			t.Errorf("expected no error on second shutdown, got %v", err)
+		}
+	})
 }
@@ -0,0 +1,138 @@
+// Command crashimport reports crash logs of Go programs that died of a panic
+// or fatal error, such as the stderr of a service kept by its supervisor:
+//
+//	crashimport -webhook https://tracker.example.com/webhook -license LICENSE_ID \
+//		-app billing -version v1.4.2 /var/log/billing/crash-*.log
+//
+// Without files it reads standard input. Each event is timestamped with the
+// modification time of its file. With -dry-run the events are printed as
+// JSON instead of sent. The events carry the -app, -version and -device flags
+// but nothing about the host running crashimport, so -version is required.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/core/panicparse"
+	"github.com/royaltics/tracker-go/types"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("crashimport", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	config := &types.ClientConfig{Enabled: true}
+	flags.StringVar(&config.WebhookURL, "webhook", "", "webhook URL of the tracker")
+	flags.StringVar(&config.LicenseID, "license", "", "license ID")
+	flags.StringVar(&config.LicenseName, "license-name", "", "license name")
+	flags.StringVar(&config.LicenseDevice, "device", "", "device the logs come from")
+	flags.StringVar(&config.App, "app", "", "application name")
+	flags.StringVar(&config.Version, "version", "", "release of the program that crashed")
+	flags.StringVar(&config.Platform, "platform", "", "platform name")
+	dryRun := flags.Bool("dry-run", false, "print the events instead of sending them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	inputs := flags.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+
+	var client *errortracker.ErrorTrackerClient
+	if !*dryRun {
+		if config.Version == "" {
+			fmt.Fprintln(stderr, "crashimport: -version is required")
+			return 2
+		}
+		var err error
+		client, err = errortracker.NewClient(config)
+		if err != nil {
+			fmt.Fprintf(stderr, "crashimport: %v\n", err)
+			return 2
+		}
+	}
+
+	imported := 0
+	for _, input := range inputs {
+		output, modTime, err := readInput(input, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "crashimport: %v\n", err)
+			continue
+		}
+
+		found := false
+		if *dryRun {
+			found = printEvent(stdout, output)
+		} else {
+			found = client.CaptureCrash(context.Background(), output, crashLog(input, modTime))
+		}
+		if !found {
+			fmt.Fprintf(stderr, "crashimport: no crash in %s\n", input)
+			continue
+		}
+		imported++
+	}
+
+	if client != nil {
+		if err := client.Shutdown(); err != nil {
+			fmt.Fprintf(stderr, "crashimport: %v\n", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(stderr, "imported %d of %d crash logs\n", imported, len(inputs))
+	if imported < len(inputs) {
+		return 1
+	}
+	return 0
+}
+
+func readInput(input string, stdin io.Reader) ([]byte, time.Time, error) {
+	if input == "-" {
+		output, err := io.ReadAll(stdin)
+		return output, time.Time{}, err
+	}
+
+	info, err := os.Stat(input)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	output, err := os.ReadFile(input)
+	return output, info.ModTime(), err
+}
+
+// crashLog records where the crash was read from and dates the event to it.
+func crashLog(input string, modTime time.Time) errortracker.CaptureOption {
+	return func(scope *core.Scope) {
+		scope.SetExtra("crash_log", input)
+		if modTime.IsZero() {
+			return
+		}
+		scope.AddEventProcessor(func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+			event.Timestamp = modTime.UTC().Format(time.RFC3339)
+			return event
+		})
+	}
+}
+
+func printEvent(stdout io.Writer, output []byte) bool {
+	crash, ok := panicparse.Parse(output)
+	if !ok {
+		return false
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(crash.Event())
+	return true
+}
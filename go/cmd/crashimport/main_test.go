@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const sampleCrash = "panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:9 +0x1d\n"
+
+func TestRun(t *testing.T) {
+	t.Run("should print the events of a dry run", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "crash.log")
+		if err := os.WriteFile(path, []byte(sampleCrash), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var stdout, stderr bytes.Buffer
+
+		code := run([]string{"-dry-run", path}, strings.NewReader(""), &stdout, &stderr)
+
+		if code != 0 {
+			t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+		}
+		var event types.EventIssue
+		if err := json.Unmarshal(stdout.Bytes(), &event); err != nil {
+			t.Fatalf("expected an event, got %v", err)
+		}
+		if event.Title != "panic: boom" || event.Context.Culprit != "main.main:9" {
+			t.Errorf("unexpected event %s %s", event.Title, event.Context.Culprit)
+		}
+	})
+
+	t.Run("should fail on input without a crash", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		code := run([]string{"-dry-run"}, strings.NewReader("exit status 1\n"), &stdout, &stderr)
+
+		if code != 1 || !strings.Contains(stderr.String(), "no crash in -") {
+			t.Errorf("expected exit code 1 and a message, got %d %q", code, stderr.String())
+		}
+	})
+
+	t.Run("should require the version of the crashed program", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		args := []string{"-webhook", "https://api.example.com/webhook", "-license", "test-license"}
+
+		code := run(args, strings.NewReader(sampleCrash), &stdout, &stderr)
+
+		if code != 2 || !strings.Contains(stderr.String(), "-version is required") {
+			t.Errorf("expected exit code 2 and a message, got %d %q", code, stderr.String())
+		}
+	})
+
+	t.Run("should require a valid config", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		if code := run(nil, strings.NewReader(sampleCrash), &stdout, &stderr); code != 2 {
+			t.Errorf("expected exit code 2, got %d", code)
+		}
+	})
+}
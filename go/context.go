@@ -0,0 +1,64 @@
+package errortracker
+
+import (
+	"context"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type clientContextKey struct{}
+
+func ContextWithClient(ctx context.Context, client *ErrorTrackerClient) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+func ClientFromContext(ctx context.Context) *ErrorTrackerClient {
+	if ctx == nil {
+		return nil
+	}
+	client, _ := ctx.Value(clientContextKey{}).(*ErrorTrackerClient)
+	return client
+}
+
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	return withScope(ctx, func(scope *core.Scope) {
+		scope.SetTags(tags)
+	})
+}
+
+func WithExtra(ctx context.Context, extra map[string]string) context.Context {
+	return withScope(ctx, func(scope *core.Scope) {
+		scope.SetExtras(extra)
+	})
+}
+
+func WithUser(ctx context.Context, user types.User) context.Context {
+	return withScope(ctx, func(scope *core.Scope) {
+		scope.SetUser(user)
+	})
+}
+
+// withScope derives a new scope from the one already stored in ctx so that
+// values added by a child context never leak into its parent.
+func withScope(ctx context.Context, configure func(scope *core.Scope)) context.Context {
+	scope := core.NewScope()
+	if parent := core.ScopeFromContext(ctx); parent != nil {
+		scope = parent.Clone()
+	}
+	configure(scope)
+	return core.ContextWithScope(ctx, scope)
+}
+
+func clientFor(ctx context.Context) (*ErrorTrackerClient, error) {
+	if client := ClientFromContext(ctx); client != nil {
+		return client, nil
+	}
+	if hub := HubFromContext(ctx); hub != nil {
+		return hub.client, nil
+	}
+	return Get()
+}
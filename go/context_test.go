@@ -0,0 +1,189 @@
+package errortracker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func newContextTestClient(t *testing.T) *ErrorTrackerClient {
+	t.Helper()
+
+	config := &types.ClientConfig{
+		WebhookURL:    "https://api.example.com/webhook",
+		LicenseID:     "test-license",
+		LicenseDevice: "test-device",
+		Enabled:       true,
+		MaxRetries:    3,
+		Timeout:       10 * time.Second,
+		FlushInterval: 5 * time.Second,
+		MaxQueueSize:  50,
+	}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return client
+}
+
+func lastQueuedEvent(t *testing.T, client *ErrorTrackerClient) types.EventIssue {
+	t.Helper()
+
+	client.queueMu.Lock()
+	defer client.queueMu.Unlock()
+
+	if len(client.eventQueue) == 0 {
+		t.Fatal("expected an event to be queued")
+	}
+	return client.eventQueue[len(client.eventQueue)-1]
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTagKey(tags []string, key string) bool {
+	for _, t := range tags {
+		if strings.HasPrefix(t, key+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestErrorCtx(t *testing.T) {
+	t.Run("should merge scope from context", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		ctx := WithTags(context.Background(), map[string]string{"route": "/orders"})
+		ctx = WithExtra(ctx, map[string]string{"requestId": "abc"})
+		ctx = WithUser(ctx, types.User{ID: "42"})
+
+		client.ErrorCtx(ctx, errors.New("test error"), types.LevelError, map[string]string{
+			"orderId": "123",
+		})
+
+		event := lastQueuedEvent(t, client)
+
+		if event.Context.Extra["requestId"] != "abc" {
+			t.Errorf("expected requestId extra, got %v", event.Context.Extra)
+		}
+		if event.Context.Extra["orderId"] != "123" {
+			t.Errorf("expected orderId extra, got %v", event.Context.Extra)
+		}
+		if !hasTag(event.Context.Tags, "route:/orders") {
+			t.Errorf("expected route tag, got %v", event.Context.Tags)
+		}
+		if event.Context.User == nil || event.Context.User.ID != "42" {
+			t.Errorf("expected user to be set, got %v", event.Context.User)
+		}
+	})
+
+	t.Run("should let call-site metadata override scope", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		ctx := WithExtra(context.Background(), map[string]string{"key": "scope"})
+		client.ErrorCtx(ctx, errors.New("test error"), types.LevelError, map[string]string{
+			"key": "call",
+		})
+
+		event := lastQueuedEvent(t, client)
+		if event.Context.Extra["key"] != "call" {
+			t.Errorf("expected call-site value, got %s", event.Context.Extra["key"])
+		}
+	})
+
+	t.Run("should not mutate caller metadata", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		metadata := map[string]string{"key": "value"}
+		ctx := WithExtra(context.Background(), map[string]string{"other": "value"})
+		client.ErrorCtx(ctx, errors.New("test error"), types.LevelError, metadata)
+
+		if len(metadata) != 1 {
+			t.Errorf("expected metadata to be untouched, got %v", metadata)
+		}
+	})
+
+	t.Run("should not leak child scope into parent context", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		parent := WithTags(context.Background(), map[string]string{"a": "1"})
+		_ = WithTags(parent, map[string]string{"b": "2"})
+
+		client.ErrorCtx(parent, errors.New("test error"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if hasTag(event.Context.Tags, "b:2") {
+			t.Errorf("expected child tag to stay in child context, got %v", event.Context.Tags)
+		}
+	})
+
+	t.Run("should use client from context", func(t *testing.T) {
+		Shutdown()
+
+		client := newContextTestClient(t)
+		ctx := ContextWithClient(context.Background(), client)
+
+		if err := ErrorCtx(ctx, errors.New("test error"), types.LevelError, nil); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := EventCtx(ctx, "test event", types.LevelInfo, nil); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		client.queueMu.Lock()
+		queueLen := len(client.eventQueue)
+		client.queueMu.Unlock()
+
+		if queueLen != 2 {
+			t.Errorf("expected queue length to be 2, got %d", queueLen)
+		}
+	})
+
+	t.Run("should return error without client", func(t *testing.T) {
+		Shutdown()
+
+		if err := ErrorCtx(context.Background(), errors.New("test error"), types.LevelError, nil); err == nil {
+			t.Error("expected error when no instance")
+		}
+	})
+}
+
+func TestEventCtx(t *testing.T) {
+	t.Run("should merge scope from context", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		ctx := WithTags(context.Background(), map[string]string{"worker": "billing"})
+		client.EventCtx(ctx, "job finished", types.LevelInfo, nil)
+
+		event := lastQueuedEvent(t, client)
+		if !hasTag(event.Context.Tags, "worker:billing") {
+			t.Errorf("expected worker tag, got %v", event.Context.Tags)
+		}
+	})
+
+	t.Run("should report caller as culprit", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.EventCtx(context.Background(), "job finished", types.LevelInfo, nil)
+
+		event := lastQueuedEvent(t, client)
+		if event.Context.Culprit == "" || event.Context.Culprit == "Unknown" {
+			t.Fatalf("expected culprit, got %q", event.Context.Culprit)
+		}
+		if want := "TestEventCtx"; !strings.Contains(event.Context.Culprit, want) {
+			t.Errorf("expected culprit to contain %q, got %q", want, event.Context.Culprit)
+		}
+	})
+}
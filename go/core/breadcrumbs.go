@@ -0,0 +1,80 @@
+package core
+
+import (
+	"github.com/royaltics/tracker-go/types"
+)
+
+// BreadcrumbBuffer is a fixed-size ring buffer that keeps the most recent
+// breadcrumbs and silently drops the oldest ones once full.
+type BreadcrumbBuffer struct {
+	items []types.Breadcrumb
+	start int
+	size  int
+}
+
+func NewBreadcrumbBuffer(limit int) *BreadcrumbBuffer {
+	if limit < 0 {
+		limit = 0
+	}
+	return &BreadcrumbBuffer{
+		items: make([]types.Breadcrumb, limit),
+	}
+}
+
+func (b *BreadcrumbBuffer) Add(breadcrumb types.Breadcrumb) {
+	limit := len(b.items)
+	if limit == 0 {
+		return
+	}
+
+	if b.size < limit {
+		b.items[(b.start+b.size)%limit] = breadcrumb
+		b.size++
+		return
+	}
+
+	b.items[b.start] = breadcrumb
+	b.start = (b.start + 1) % limit
+}
+
+func (b *BreadcrumbBuffer) Len() int {
+	return b.size
+}
+
+func (b *BreadcrumbBuffer) Limit() int {
+	return len(b.items)
+}
+
+// Items returns the breadcrumbs from oldest to newest.
+func (b *BreadcrumbBuffer) Items() []types.Breadcrumb {
+	items := make([]types.Breadcrumb, b.size)
+	for i := 0; i < b.size; i++ {
+		items[i] = b.items[(b.start+i)%len(b.items)]
+	}
+	return items
+}
+
+// Resize changes the capacity, keeping the newest breadcrumbs that still fit.
+func (b *BreadcrumbBuffer) Resize(limit int) {
+	if limit == len(b.items) {
+		return
+	}
+
+	items := b.Items()
+	if limit < 0 {
+		limit = 0
+	}
+	if len(items) > limit {
+		items = items[len(items)-limit:]
+	}
+
+	b.items = make([]types.Breadcrumb, limit)
+	b.start = 0
+	b.size = copy(b.items, items)
+}
+
+func (b *BreadcrumbBuffer) Clear() {
+	b.items = make([]types.Breadcrumb, len(b.items))
+	b.start = 0
+	b.size = 0
+}
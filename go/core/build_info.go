@@ -0,0 +1,79 @@
+package core
+
+import (
+	"runtime/debug"
+	"strings"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const shortRevisionLength = 12
+
+// BuildContext returns the main module and version control details stamped
+// into the binary by the go command, or nil without build info.
+func BuildContext(info *debug.BuildInfo) *types.BuildContext {
+	if info == nil {
+		return nil
+	}
+
+	build := &types.BuildContext{
+		Module:    info.Main.Path,
+		GoVersion: info.GoVersion,
+	}
+	if info.Main.Version != "(devel)" {
+		build.Version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Revision = setting.Value
+		case "vcs.time":
+			build.Time = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+	return build
+}
+
+// Release derives a release name from the build: the module version when it
+// is a tagged or pseudo version, otherwise the short VCS revision. Local
+// changes add a "+dirty" suffix, as the go command does for versions.
+func Release(build *types.BuildContext) string {
+	if build == nil {
+		return ""
+	}
+	if build.Version != "" {
+		return build.Version
+	}
+	if build.Revision == "" {
+		return ""
+	}
+
+	release := build.Revision
+	if len(release) > shortRevisionLength {
+		release = release[:shortRevisionLength]
+	}
+	if build.Modified {
+		release += "+dirty"
+	}
+	return release
+}
+
+// Modules lists the dependencies of the binary with their versions. Replaced
+// modules report the replacement.
+func Modules(info *debug.BuildInfo) map[string]string {
+	if info == nil {
+		return nil
+	}
+
+	modules := make(map[string]string, len(info.Deps))
+	for _, dep := range info.Deps {
+		version := dep.Version
+		if dep.Replace != nil {
+			version = strings.TrimSpace("=> " + dep.Replace.Path + " " + dep.Replace.Version)
+		}
+		modules[dep.Path] = version
+	}
+	return modules
+}
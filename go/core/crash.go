@@ -0,0 +1,58 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/royaltics/tracker-go/core/panicparse"
+	"github.com/royaltics/tracker-go/types"
+)
+
+// BuildCrash builds a FATAL event from the output a Go program writes to
+// stderr when it dies of a panic or fatal error. It reports false when the
+// output holds no crash.
+func (eb *EventBuilder) BuildCrash(ctx context.Context, output []byte, scopes ...*Scope) (types.EventIssue, bool) {
+	crash, ok := panicparse.Parse(output)
+	if !ok {
+		return types.EventIssue{}, false
+	}
+	parsed := crash.Event()
+
+	event := eb.buildCrash(ctx, parsed.Title, parsed.Event.Name, parsed.Event.Message, scopes)
+	event.Event.Stack = parsed.Event.Stack
+	event.Event.Frames = eb.resolveFrames(parsed.Event.Frames)
+	event.Event.Exceptions = parsed.Event.Exceptions
+	event.Event.Extra = parsed.Event.Extra
+	event.Context.Culprit = parsed.Context.Culprit
+	event.Goroutines = eb.resolveDump(parsed.Goroutines)
+
+	resolveFingerprint(&event, "")
+	return event, true
+}
+
+// BuildSignal builds a FATAL event for a process killed by sig without
+// printing a crash, as done by the OOM killer.
+func (eb *EventBuilder) BuildSignal(ctx context.Context, sig os.Signal, scopes ...*Scope) types.EventIssue {
+	message := fmt.Sprintf("process killed by signal: %v", sig)
+	event := eb.buildCrash(ctx, message, "signal", message, scopes)
+	resolveFingerprint(&event, "")
+	return event
+}
+
+// buildCrash builds an event about another process, so the stack, runtime
+// context and goroutines of this one are left out. There is no Go error
+// behind it, so it carries no error type tag.
+func (eb *EventBuilder) buildCrash(ctx context.Context, title, name, message string, scopes []*Scope) types.EventIssue {
+	event := eb.buildEvent(ctx, title, nil, types.LevelFatal, nil, scopes)
+	event.Event.Name = name
+	event.Event.Message = message
+	event.Event.Mechanism = &types.Mechanism{
+		Type:    "crash",
+		Handled: false,
+	}
+	event.Event.Stack = ""
+	event.Event.Frames = nil
+	event.Context.Culprit = "Unknown"
+	return event
+}
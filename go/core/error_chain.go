@@ -0,0 +1,62 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const defaultMaxErrorDepth = 10
+
+func unwrapErrors(err error) []error {
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() []error }:
+		return wrapper.Unwrap()
+	case interface{ Unwrap() error }:
+		if inner := wrapper.Unwrap(); inner != nil {
+			return []error{inner}
+		}
+	}
+	return nil
+}
+
+// unwrapChain flattens the error tree in depth-first order, so the outermost
+// error comes first and each wrapped error follows the one wrapping it.
+// Errors deeper than maxDepth are left out.
+func unwrapChain(err error, maxDepth int) []types.Exception {
+	var exceptions []types.Exception
+
+	var walk func(err error, parent, depth int)
+	walk = func(err error, parent, depth int) {
+		if err == nil || depth >= maxDepth {
+			return
+		}
+
+		index := len(exceptions)
+		exceptions = append(exceptions, types.Exception{
+			Name:    fmt.Sprintf("%T", err),
+			Message: err.Error(),
+			Parent:  parent,
+		})
+
+		for _, inner := range unwrapErrors(err) {
+			walk(inner, index, depth+1)
+		}
+	}
+	walk(err, -1, 0)
+
+	return exceptions
+}
+
+// rootCause follows the first wrapped error at every level. For errors.Join
+// that is the first joined error.
+func rootCause(err error, maxDepth int) error {
+	for depth := 1; depth < maxDepth; depth++ {
+		inner := unwrapErrors(err)
+		if len(inner) == 0 || inner[0] == nil {
+			break
+		}
+		err = inner[0]
+	}
+	return err
+}
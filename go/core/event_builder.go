@@ -1,37 +1,180 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/royaltics/tracker-go/types"
 )
 
+const sdkModule = "github.com/royaltics/tracker-go"
+
 type EventBuilder struct {
-	app      string
-	version  string
-	platform string
-	device   string
+	app           string
+	version       string
+	platform      string
+	device        string
+	inAppPrefixes []string
+	maxErrorDepth int
+	limits        types.MetadataLimits
+	runtime       *runtimeCollector
+	host          *types.HostContext
+	os            *types.OSContext
+	buildContext  *types.BuildContext
+	modules       map[string]string
+	sources       *sourceCache
+
+	attachGoroutines   bool
+	goroutineDumpBytes int
 }
 
 func NewEventBuilder(app, version, platform, device string) *EventBuilder {
 	return &EventBuilder{
-		app:      app,
-		version:  version,
-		platform: platform,
-		device:   device,
+		app:           app,
+		version:       version,
+		platform:      platform,
+		device:        device,
+		maxErrorDepth: defaultMaxErrorDepth,
+		runtime:       newRuntimeCollector(defaultMemStatsMaxAge),
+	}
+}
+
+// SetInAppPrefixes sets the package path prefixes whose frames are flagged
+// as application code.
+func (eb *EventBuilder) SetInAppPrefixes(prefixes ...string) *EventBuilder {
+	eb.inAppPrefixes = prefixes
+	return eb
+}
+
+// SetMaxErrorDepth limits how many levels of wrapped errors are unwrapped.
+// Values below 1 restore the default.
+func (eb *EventBuilder) SetMaxErrorDepth(depth int) *EventBuilder {
+	if depth < 1 {
+		depth = defaultMaxErrorDepth
+	}
+	eb.maxErrorDepth = depth
+	return eb
+}
+
+// SetMemStatsMaxAge sets how long memory stats are reused between events.
+// Zero restores the default and a negative value leaves them out.
+func (eb *EventBuilder) SetMemStatsMaxAge(maxAge time.Duration) *EventBuilder {
+	eb.runtime = newRuntimeCollector(maxAge)
+	return eb
+}
+
+// SetHost sets the host and OS context attached to every event. The host
+// name also replaces an empty device.
+func (eb *EventBuilder) SetHost(host *types.HostContext, osContext *types.OSContext) *EventBuilder {
+	eb.host = host
+	eb.os = osContext
+	return eb
+}
+
+// SetBuild sets the build context and, optionally, the module list attached
+// to every event.
+func (eb *EventBuilder) SetBuild(build *types.BuildContext, modules map[string]string) *EventBuilder {
+	eb.buildContext = build
+	eb.modules = modules
+	return eb
+}
+
+// SetSourceContext reads lines of source around in-app frames, caching up to
+// cacheBytes of files. Zero lines disables it.
+func (eb *EventBuilder) SetSourceContext(lines, cacheBytes int) *EventBuilder {
+	eb.sources = nil
+	if lines > 0 {
+		eb.sources = newSourceCache(lines, cacheBytes)
+	}
+	return eb
+}
+
+// SetGoroutineDump attaches the stacks of all goroutines to FATAL events,
+// reading at most maxBytes of stack output.
+func (eb *EventBuilder) SetGoroutineDump(enabled bool, maxBytes int) *EventBuilder {
+	eb.attachGoroutines = enabled
+	eb.goroutineDumpBytes = maxBytes
+	return eb
+}
+
+// SetMetadataLimits bounds the metadata attached to built events.
+func (eb *EventBuilder) SetMetadataLimits(limits types.MetadataLimits) *EventBuilder {
+	eb.limits = limits
+	return eb
+}
+
+// Sanitize makes the event metadata safe to serialize. Build already calls
+// it; it only needs to run again after the event was modified.
+func (eb *EventBuilder) Sanitize(event *types.EventIssue) {
+	event.Context.Extra = SanitizeExtra(event.Context.Extra, eb.limits)
+	if user := event.Context.User; user != nil && user.Data != nil {
+		sanitized := *user
+		sanitized.Data = SanitizeExtra(user.Data, eb.limits)
+		event.Context.User = &sanitized
 	}
 }
 
 func (eb *EventBuilder) Build(
+	ctx context.Context,
 	title string,
 	err error,
 	level types.EventLevel,
-	extra map[string]string,
+	extra map[string]any,
+	scopes ...*Scope,
+) types.EventIssue {
+	event := eb.build(ctx, title, err, level, extra, scopes)
+	resolveFingerprint(&event, "")
+	return event
+}
+
+// BuildMessage builds an event for a message without an error, such as a log
+// record. Messages have no error type to group by, so the normalized message
+// is part of the default fingerprint.
+func (eb *EventBuilder) BuildMessage(
+	ctx context.Context,
+	title string,
+	level types.EventLevel,
+	extra map[string]any,
+	scopes ...*Scope,
+) types.EventIssue {
+	event := eb.build(ctx, title, fmt.Errorf("%s", title), level, extra, scopes)
+	resolveFingerprint(&event, normalizeMessage(title))
+	return event
+}
+
+func (eb *EventBuilder) build(
+	ctx context.Context,
+	title string,
+	err error,
+	level types.EventLevel,
+	extra map[string]any,
+	scopes []*Scope,
+) types.EventIssue {
+	event := eb.buildEvent(ctx, title, err, level, extra, scopes)
+	fatal := event.Level == string(types.LevelFatal)
+	event.Context.Runtime = eb.runtime.collect(fatal)
+	if eb.attachGoroutines && fatal {
+		event.Goroutines = eb.goroutineDump()
+	}
+	return event
+}
+
+// buildEvent builds an event without the runtime context and goroutine dump
+// of this process.
+func (eb *EventBuilder) buildEvent(
+	ctx context.Context,
+	title string,
+	err error,
+	level types.EventLevel,
+	extra map[string]any,
+	scopes []*Scope,
 ) types.EventIssue {
 	culprit := eb.extractCulprit()
 	serializedError := eb.serializeError(err)
@@ -43,6 +186,9 @@ func (eb *EventBuilder) Build(
 	}
 
 	device := eb.device
+	if device == "" && eb.host != nil {
+		device = eb.host.Hostname
+	}
 	if device == "" {
 		device = os.Getenv("HOSTNAME")
 		if device == "" {
@@ -50,7 +196,7 @@ func (eb *EventBuilder) Build(
 		}
 	}
 
-	return types.EventIssue{
+	event := types.EventIssue{
 		EventID:   uuid.New().String(),
 		Title:     title,
 		Level:     string(level),
@@ -58,14 +204,55 @@ func (eb *EventBuilder) Build(
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Context: types.EventContext{
 			Culprit:  culprit,
-			Extra:    extra,
+			Extra:    copyValues(extra),
 			Platform: platform,
 			App:      eb.app,
 			Version:  eb.version,
 			Device:   device,
 			Tags:     tags,
+			Host:     copyHost(eb.host),
+			OS:       copyOS(eb.os),
+			Build:    copyBuild(eb.buildContext),
+			Modules:  maps.Clone(eb.modules),
 		},
 	}
+
+	scopes = append([]*Scope{ScopeFromContext(ctx)}, scopes...)
+	if scope := mergeScopes(scopes...); scope != nil {
+		scope.ApplyToEvent(&event)
+	}
+	if event.Level == "" {
+		event.Level = string(types.LevelError)
+	}
+	eb.Sanitize(&event)
+
+	return event
+}
+
+// BuildPanic builds a FATAL event for a recovered panic value. It must be
+// called from the deferred function that recovered so the captured stack is
+// still the one of the panicking goroutine.
+func (eb *EventBuilder) BuildPanic(ctx context.Context, value any, scopes ...*Scope) types.EventIssue {
+	err, isError := value.(error)
+	if !isError {
+		err = fmt.Errorf("%v", value)
+	}
+
+	event := eb.build(ctx, fmt.Sprintf("panic: %v", value), err, types.LevelFatal, nil, scopes)
+	if !isError {
+		event.Event.Name = fmt.Sprintf("%T", value)
+	}
+	event.Event.Mechanism = &types.Mechanism{
+		Type:    "panic",
+		Handled: false,
+	}
+	if culprit := eb.extractPanicCulprit(); culprit != "" {
+		event.Context.Culprit = culprit
+	}
+	event.Event.Frames = eb.extractFrames(panicCallers(callers(0)))
+	resolveFingerprint(&event, "")
+
+	return event
 }
 
 func (eb *EventBuilder) Stringify(event types.EventIssue) (string, error) {
@@ -77,18 +264,109 @@ func (eb *EventBuilder) Stringify(event types.EventIssue) (string, error) {
 }
 
 func (eb *EventBuilder) extractCulprit() string {
-	pc := make([]uintptr, 10)
-	n := runtime.Callers(4, pc)
-	
-	if n > 0 {
-		frames := runtime.CallersFrames(pc[:n])
-		frame, _ := frames.Next()
-		return fmt.Sprintf("%s:%d", frame.Function, frame.Line)
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(2, pc)
+
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isSDKFrame(frame) {
+			return fmt.Sprintf("%s:%d", frame.Function, frame.Line)
+		}
+		if !more {
+			break
+		}
 	}
-	
+
 	return "Unknown"
 }
 
+// extractPanicCulprit returns the first frame below runtime.gopanic, which is
+// the function that panicked.
+func (eb *EventBuilder) extractPanicCulprit() string {
+	pc := make([]uintptr, 64)
+	n := runtime.Callers(2, pc)
+
+	frames := runtime.CallersFrames(pc[:n])
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		} else if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return fmt.Sprintf("%s:%d", frame.Function, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+
+	return ""
+}
+
+// isSDKFrame reports frames of this module and of the logging packages that
+// forward to it, which are never the origin of an event.
+func isSDKFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(frame.Function, sdkModule+".") ||
+		strings.HasPrefix(frame.Function, sdkModule+"/") ||
+		strings.HasPrefix(frame.Function, "log/slog.")
+}
+
+func copyExtra(extra map[string]string) map[string]string {
+	if extra == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(extra))
+	for key, value := range extra {
+		copied[key] = value
+	}
+	return copied
+}
+
+// copyHost and copyOS give every event its own copy, so processors can edit
+// them safely.
+func copyHost(host *types.HostContext) *types.HostContext {
+	if host == nil {
+		return nil
+	}
+	copied := *host
+	if host.Kubernetes != nil {
+		kubernetes := *host.Kubernetes
+		copied.Kubernetes = &kubernetes
+	}
+	return &copied
+}
+
+func copyOS(osContext *types.OSContext) *types.OSContext {
+	if osContext == nil {
+		return nil
+	}
+	copied := *osContext
+	return &copied
+}
+
+func copyBuild(build *types.BuildContext) *types.BuildContext {
+	if build == nil {
+		return nil
+	}
+	copied := *build
+	return &copied
+}
+
+func copyValues(extra map[string]any) map[string]any {
+	if extra == nil {
+		return nil
+	}
+	copied := make(map[string]any, len(extra))
+	for key, value := range extra {
+		copied[key] = value
+	}
+	return copied
+}
+
 func (eb *EventBuilder) serializeError(err error) types.SerializedError {
 	if err == nil {
 		return types.SerializedError{
@@ -99,24 +377,41 @@ func (eb *EventBuilder) serializeError(err error) types.SerializedError {
 
 	stack := eb.getStackTrace()
 
-	return types.SerializedError{
-		Name:    fmt.Sprintf("%T", err),
+	pcs := errorCallers(err, eb.maxErrorDepth)
+	if pcs == nil {
+		pcs = callers(0)
+	}
+
+	serialized := types.SerializedError{
+		Name:    fmt.Sprintf("%T", rootCause(err, eb.maxErrorDepth)),
 		Message: err.Error(),
 		Stack:   stack,
+		Frames:  eb.extractFrames(pcs),
+	}
+	if exceptions := unwrapChain(err, eb.maxErrorDepth); len(exceptions) > 1 {
+		serialized.Exceptions = exceptions
 	}
+	return serialized
 }
 
+// getStackTrace grows its buffer until the whole trace of the current
+// goroutine fits, up to 1 MB.
 func (eb *EventBuilder) getStackTrace() string {
 	buf := make([]byte, 4096)
-	n := runtime.Stack(buf, false)
-	return string(buf[:n])
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) || len(buf) >= 1<<20 {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
+	}
 }
 
 func (eb *EventBuilder) extractTags(err error) []string {
 	tags := []string{}
 	
 	if err != nil {
-		tags = append(tags, fmt.Sprintf("error:%T", err))
+		tags = append(tags, fmt.Sprintf("error:%T", rootCause(err, eb.maxErrorDepth)))
 	}
 	
 	return tags
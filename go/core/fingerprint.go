@@ -0,0 +1,74 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+// DefaultFingerprint stands for the computed fingerprint inside a custom one,
+// so callers can refine the default grouping instead of replacing it.
+const DefaultFingerprint = "{{ default }}"
+
+// messageVariables matches the parts of a message that change between
+// occurrences of the same problem: UUIDs, hex values and numbers.
+var messageVariables = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b|\b0x[0-9a-f]+\b|\d+`)
+
+// resolveFingerprint sets the default fingerprint on events without one and
+// expands DefaultFingerprint in custom ones. A non-empty message is part of
+// the default, for events that have no error type to group by.
+func resolveFingerprint(event *types.EventIssue, message string) {
+	if len(event.Fingerprint) == 0 {
+		event.Fingerprint = []string{defaultFingerprint(event, message)}
+		return
+	}
+
+	for i, part := range event.Fingerprint {
+		if isDefaultFingerprint(part) {
+			event.Fingerprint[i] = defaultFingerprint(event, message)
+		}
+	}
+}
+
+// normalizeMessage replaces IDs and numbers, so "order 123 failed" and
+// "order 456 failed" share a fingerprint.
+func normalizeMessage(message string) string {
+	return messageVariables.ReplaceAllString(message, "?")
+}
+
+func isDefaultFingerprint(part string) bool {
+	return strings.Join(strings.Fields(part), "") == "{{default}}"
+}
+
+// defaultFingerprint hashes the primary error type with the in-app frames.
+// Line numbers and error messages are left out so events stay grouped across
+// deployments and variable data such as IDs. Without in-app frames every
+// frame is used, and without frames the error message.
+func defaultFingerprint(event *types.EventIssue, message string) string {
+	parts := []string{event.Event.Name}
+	if message != "" {
+		parts = append(parts, message)
+	}
+
+	var frames []string
+	for _, frame := range event.Event.Frames {
+		if frame.InApp {
+			frames = append(frames, frame.Package+"."+frame.Function)
+		}
+	}
+	if len(frames) == 0 {
+		for _, frame := range event.Event.Frames {
+			frames = append(frames, frame.Package+"."+frame.Function)
+		}
+	}
+	if len(frames) == 0 {
+		frames = append(frames, event.Event.Message)
+	}
+	parts = append(parts, frames...)
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
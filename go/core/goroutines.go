@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/royaltics/tracker-go/core/panicparse"
+	"github.com/royaltics/tracker-go/types"
+)
+
+const defaultGoroutineDumpBytes = 1 << 20
+
+// goroutineDump captures the stacks of all goroutines, up to maxBytes of
+// runtime.Stack output, and groups the identical ones.
+func (eb *EventBuilder) goroutineDump() *types.GoroutineDump {
+	maxBytes := eb.goroutineDumpBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultGoroutineDumpBytes
+	}
+
+	buf := make([]byte, min(64<<10, maxBytes))
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxBytes {
+			goroutines, truncated := panicparse.ParseGoroutines(buf[:n])
+			return eb.resolveDump(panicparse.Group(goroutines, truncated || n == len(buf)))
+		}
+		buf = make([]byte, min(len(buf)*2, maxBytes))
+	}
+}
+
+// BuildGoroutineDump builds an event carrying the stacks of all goroutines,
+// as printed by the runtime on SIGQUIT, whatever the level.
+func (eb *EventBuilder) BuildGoroutineDump(ctx context.Context, title string, level types.EventLevel, extra map[string]any, scopes ...*Scope) types.EventIssue {
+	event := eb.build(ctx, title, nil, level, extra, scopes)
+	event.Event.Name = "goroutine dump"
+	event.Event.Message = title
+	// The stack of the goroutine asking for the dump says nothing.
+	event.Event.Stack = ""
+	event.Event.Frames = nil
+	event.Context.Culprit = "Unknown"
+	event.Goroutines = eb.goroutineDump()
+	resolveFingerprint(&event, "")
+	return event
+}
+
+// resolveDump resolves the frames of every group in dump.
+func (eb *EventBuilder) resolveDump(dump *types.GoroutineDump) *types.GoroutineDump {
+	if dump == nil {
+		return nil
+	}
+	for i := range dump.Groups {
+		group := &dump.Groups[i]
+		group.Frames = eb.resolveFrames(group.Frames)
+		if group.CreatedBy != nil {
+			eb.resolveFrame(group.CreatedBy)
+		}
+	}
+	return dump
+}
+
+// resolveFrames shortens the paths of frames parsed from text, whose AbsPath
+// is the printed path, and marks the in-app ones.
+func (eb *EventBuilder) resolveFrames(frames []types.StackFrame) []types.StackFrame {
+	for i := range frames {
+		eb.resolveFrame(&frames[i])
+	}
+	return frames
+}
+
+func (eb *EventBuilder) resolveFrame(frame *types.StackFrame) {
+	frame.File = relativePath(frame.AbsPath, frame.Package)
+	frame.InApp = eb.isInApp(frame.Package, frame.AbsPath)
+}
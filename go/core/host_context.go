@@ -0,0 +1,165 @@
+package core
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+var (
+	containerIDPattern    = regexp.MustCompile(`[0-9a-f]{64}`)
+	containerMountPattern = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+)
+
+var (
+	localHostOnce sync.Once
+	localHost     *types.HostContext
+	localOS       *types.OSContext
+)
+
+// LocalHost returns the host and OS context of the machine. It is detected
+// once per process.
+func LocalHost() (*types.HostContext, *types.OSContext) {
+	localHostOnce.Do(func() {
+		localHost, localOS = DetectHost(os.DirFS("/"), os.Getenv, os.Hostname)
+	})
+	return localHost, localOS
+}
+
+// DetectHost reads the host, OS, container and Kubernetes context from fsys,
+// which must be rooted at "/", the environment and the hostname lookup, such
+// as os.Hostname. Missing files are skipped, so it works on every platform.
+func DetectHost(fsys fs.FS, getenv func(string) string, lookupHostname func() (string, error)) (*types.HostContext, *types.OSContext) {
+	hostname := detectHostname(fsys, getenv, lookupHostname)
+	host := &types.HostContext{
+		Hostname:    hostname,
+		BootTime:    detectBootTime(fsys),
+		ContainerID: detectContainerID(fsys),
+		Kubernetes:  detectKubernetes(fsys, getenv, hostname),
+	}
+
+	osContext := &types.OSContext{Name: runtime.GOOS}
+	release := readOSRelease(fsys)
+	if name := release["NAME"]; name != "" {
+		osContext.Name = name
+	}
+	osContext.Version = release["VERSION_ID"]
+	osContext.PrettyName = release["PRETTY_NAME"]
+	// Same value as uname -r.
+	osContext.KernelVersion = readLine(fsys, "proc/sys/kernel/osrelease")
+
+	return host, osContext
+}
+
+func detectHostname(fsys fs.FS, getenv func(string) string, lookupHostname func() (string, error)) string {
+	if hostname, err := lookupHostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	if hostname := getenv("HOSTNAME"); hostname != "" {
+		return hostname
+	}
+	return readLine(fsys, "etc/hostname")
+}
+
+func detectBootTime(fsys fs.FS) string {
+	data, err := fs.ReadFile(fsys, "proc/stat")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		value, ok := strings.CutPrefix(line, "btime ")
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return ""
+		}
+		return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// detectContainerID looks for a container ID in the cgroup paths first, as
+// set by Docker, containerd and CRI-O with cgroup v1. With cgroup v2 the
+// path is usually "/", so the mounts of the container files are checked.
+func detectContainerID(fsys fs.FS) string {
+	if data, err := fs.ReadFile(fsys, "proc/self/cgroup"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if id := containerIDPattern.FindString(line); id != "" {
+				return id
+			}
+		}
+	}
+	if data, err := fs.ReadFile(fsys, "proc/self/mountinfo"); err == nil {
+		if match := containerMountPattern.FindStringSubmatch(string(data)); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// detectKubernetes uses the downward API variables POD_NAME, POD_NAMESPACE
+// and NODE_NAME. The pod name defaults to the hostname and the namespace to
+// the one of the service account.
+func detectKubernetes(fsys fs.FS, getenv func(string) string, hostname string) *types.KubernetesContext {
+	if getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return nil
+	}
+
+	kubernetes := &types.KubernetesContext{
+		PodName:   getenv("POD_NAME"),
+		Namespace: getenv("POD_NAMESPACE"),
+		NodeName:  getenv("NODE_NAME"),
+	}
+	if kubernetes.PodName == "" {
+		kubernetes.PodName = hostname
+	}
+	if kubernetes.Namespace == "" {
+		kubernetes.Namespace = readLine(fsys, "var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	}
+	return kubernetes
+}
+
+func readOSRelease(fsys fs.FS) map[string]string {
+	release := make(map[string]string)
+	file, err := fsys.Open("etc/os-release")
+	if err != nil {
+		if file, err = fsys.Open("usr/lib/os-release"); err != nil {
+			return release
+		}
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, `'"`)
+		}
+		release[strings.TrimSpace(key)] = value
+	}
+	return release
+}
+
+func readLine(fsys fs.FS, name string) string {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,166 @@
+package panicparse
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const (
+	maxGoroutineGroups = 100
+	maxGroupIDs        = 10
+)
+
+// Event converts the crash into a FATAL event with the frames of the
+// crashing goroutine and the other goroutines grouped. Frames keep absolute
+// paths and are not marked in-app, as that depends on the client; the
+// event builder of the core package fills those in along with the context.
+func (c *Crash) Event() types.EventIssue {
+	event := types.EventIssue{
+		Title: c.Title(),
+		Level: string(types.LevelFatal),
+		Event: types.SerializedError{
+			Name:    c.Name(),
+			Message: c.Message(),
+			Stack:   c.Raw,
+			Mechanism: &types.Mechanism{
+				Type:    "crash",
+				Handled: false,
+			},
+		},
+		Context: types.EventContext{
+			Culprit: "Unknown",
+		},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if len(c.Panics)+len(c.Nested) > 1 {
+		// The panic that killed the program comes first, then each panic
+		// raised before it, as wrapped errors follow the error wrapping them.
+		// Crashes printed after the first one are chained last.
+		chain := make([]Panic, 0, len(c.Panics)+len(c.Nested))
+		for i := len(c.Panics) - 1; i >= 0; i-- {
+			chain = append(chain, c.Panics[i])
+		}
+		chain = append(chain, c.Nested...)
+		for i, p := range chain {
+			event.Event.Exceptions = append(event.Event.Exceptions, types.Exception{
+				Name:    panicName(p.Kind, p.Value),
+				Message: p.Value,
+				Parent:  i - 1,
+			})
+		}
+	}
+	if c.Signal != "" {
+		event.Event.Extra = map[string]string{"signal": c.Signal}
+	}
+
+	if len(c.Goroutines) > 0 {
+		event.Event.Frames = StackFrames(c.Goroutines[0].Frames)
+		if culprit := crashCulprit(c.Goroutines[0].Frames); culprit != "" {
+			event.Context.Culprit = culprit
+		}
+	}
+	if len(c.Goroutines) > 1 || c.Truncated {
+		event.Goroutines = Group(c.Goroutines, c.Truncated)
+	}
+	return event
+}
+
+// StackFrame converts f, with File and AbsPath both set to the printed path.
+func (f Frame) StackFrame() types.StackFrame {
+	return types.StackFrame{
+		Function: f.Name,
+		Package:  f.Package,
+		File:     f.File,
+		AbsPath:  f.File,
+		Line:     f.Line,
+	}
+}
+
+// StackFrames converts frames, innermost first as printed.
+func StackFrames(frames []Frame) []types.StackFrame {
+	if frames == nil {
+		return nil
+	}
+	result := make([]types.StackFrame, len(frames))
+	for i, frame := range frames {
+		result[i] = frame.StackFrame()
+	}
+	return result
+}
+
+// Group collapses goroutines with the same state and stack. Groups are
+// sorted by size, and only the largest 100 are kept.
+func Group(goroutines []Goroutine, truncated bool) *types.GoroutineDump {
+	dump := &types.GoroutineDump{
+		Total:     len(goroutines),
+		Truncated: truncated,
+	}
+
+	index := make(map[string]int)
+	for _, g := range goroutines {
+		key := goroutineKey(g)
+		i, ok := index[key]
+		if !ok {
+			i = len(dump.Groups)
+			index[key] = i
+			group := types.GoroutineGroup{
+				State:  g.State,
+				Frames: StackFrames(g.Frames),
+			}
+			if g.CreatedBy != nil {
+				createdBy := g.CreatedBy.StackFrame()
+				group.CreatedBy = &createdBy
+			}
+			dump.Groups = append(dump.Groups, group)
+		}
+
+		group := &dump.Groups[i]
+		group.Count++
+		if len(group.IDs) < maxGroupIDs {
+			group.IDs = append(group.IDs, g.ID)
+		}
+		group.WaitMinutes = max(group.WaitMinutes, g.WaitMinutes)
+	}
+
+	sort.SliceStable(dump.Groups, func(i, j int) bool {
+		return dump.Groups[i].Count > dump.Groups[j].Count
+	})
+	if len(dump.Groups) > maxGoroutineGroups {
+		dump.Groups = dump.Groups[:maxGoroutineGroups]
+		dump.Truncated = true
+	}
+	return dump
+}
+
+func goroutineKey(g Goroutine) string {
+	var key strings.Builder
+	key.WriteString(g.State)
+	frames := g.Frames
+	if g.CreatedBy != nil {
+		frames = append(frames[:len(frames):len(frames)], *g.CreatedBy)
+	}
+	for _, frame := range frames {
+		key.WriteString("\n")
+		key.WriteString(frame.Function)
+		key.WriteString(":")
+		key.WriteString(strconv.Itoa(frame.Line))
+	}
+	return key.String()
+}
+
+// crashCulprit returns the first frame outside the runtime, skipping the
+// "panic" builtin.
+func crashCulprit(frames []Frame) string {
+	for _, frame := range frames {
+		if frame.Package != "" && frame.Package != "runtime" {
+			return fmt.Sprintf("%s.%s:%d", frame.Package, frame.Name, frame.Line)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+package panicparse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Goroutine is one goroutine of a crash or of runtime.Stack output.
+type Goroutine struct {
+	ID          int64
+	State       string
+	WaitMinutes int
+	Locked      bool
+	Frames      []Frame
+	// FramesElided is set when the runtime left frames out of a deep stack.
+	FramesElided bool
+	CreatedBy    *Frame
+	// CreatedByID is the creating goroutine, printed since Go 1.21.
+	CreatedByID int64
+}
+
+// Frame is a "pkg.Func(args)" line with its "file:line +0x1f" line.
+type Frame struct {
+	// Function is the full name, as in "github.com/org/repo/pkg.(*Type).Method".
+	Function string
+	Package  string
+	Name     string
+	File     string
+	Line     int
+}
+
+// ParseGoroutines reads every goroutine in dump, ignoring lines outside of
+// them. It reports whether dump ends in the middle of a goroutine, in which
+// case the last one keeps the frames read so far.
+func ParseGoroutines(dump []byte) ([]Goroutine, bool) {
+	var goroutines []Goroutine
+	var current *Goroutine
+	var function string
+	createdBy, truncated := false, false
+
+	lines := strings.Split(string(dump), "\n")
+	if last := len(lines) - 1; lines[last] != "" {
+		// A last line without a newline was cut off.
+		lines, truncated = lines[:last], true
+	}
+
+	for _, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "goroutine "):
+			current = nil
+			if g, ok := parseGoroutineHeader(line); ok {
+				goroutines = append(goroutines, g)
+				current = &goroutines[len(goroutines)-1]
+			}
+			function, createdBy = "", false
+		case current == nil || line == "":
+		case strings.HasPrefix(line, "\t"):
+			if function == "" {
+				continue
+			}
+			frame := parseFrame(function, strings.TrimPrefix(line, "\t"))
+			if createdBy {
+				current.CreatedBy = &frame
+			} else {
+				current.Frames = append(current.Frames, frame)
+			}
+			function = ""
+		case strings.HasPrefix(line, "created by "):
+			function, createdBy = strings.TrimPrefix(line, "created by "), true
+			if name, id, ok := strings.Cut(function, " in goroutine "); ok {
+				function = name
+				current.CreatedByID, _ = strconv.ParseInt(id, 10, 64)
+			}
+		case strings.HasPrefix(line, "...") && strings.HasSuffix(line, "frames elided..."):
+			current.FramesElided = true
+		case strings.HasSuffix(line, ")"):
+			function = line
+		default:
+			// Anything else, such as "exit status 2", ends the goroutine.
+			current, function = nil, ""
+		}
+	}
+
+	if current != nil && function != "" {
+		// The location line of the last frame was cut off.
+		frame := parseFrame(function, "")
+		if createdBy {
+			current.CreatedBy = &frame
+		} else {
+			current.Frames = append(current.Frames, frame)
+		}
+		truncated = true
+	}
+	return goroutines, truncated
+}
+
+// parseGoroutineHeader reads "goroutine 7 [chan receive, 3 minutes]:" and the
+// "goroutine 7 gp=0xc000007c00 m=nil [chan receive]:" form of tracebacks
+// with GOTRACEBACK=system.
+func parseGoroutineHeader(line string) (Goroutine, bool) {
+	rest := strings.TrimPrefix(line, "goroutine ")
+	idText, rest, ok := strings.Cut(rest, " ")
+	if !ok {
+		return Goroutine{}, false
+	}
+	id, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		return Goroutine{}, false
+	}
+
+	start := strings.Index(rest, "[")
+	end := strings.LastIndex(rest, "]")
+	if start < 0 || end < start {
+		return Goroutine{}, false
+	}
+
+	g := Goroutine{ID: id}
+	parts := strings.Split(rest[start+1:end], ", ")
+	g.State = parts[0]
+	for _, part := range parts[1:] {
+		if minutes, ok := strings.CutSuffix(part, " minutes"); ok {
+			g.WaitMinutes, _ = strconv.Atoi(minutes)
+		} else if part == "locked to thread" {
+			g.Locked = true
+		} else {
+			g.State += ", " + part
+		}
+	}
+	return g, true
+}
+
+func parseFrame(function, location string) Frame {
+	if open := strings.LastIndex(function, "("); open > 0 && strings.HasSuffix(function, ")") {
+		function = function[:open]
+	}
+	if offset := strings.Index(location, " +0x"); offset >= 0 {
+		location = location[:offset]
+	}
+
+	file, line := location, 0
+	if colon := strings.LastIndex(location, ":"); colon >= 0 {
+		if n, err := strconv.Atoi(location[colon+1:]); err == nil {
+			file, line = location[:colon], n
+		}
+	}
+
+	pkg, name := SplitFunctionName(function)
+	return Frame{
+		Function: function,
+		Package:  pkg,
+		Name:     name,
+		File:     file,
+		Line:     line,
+	}
+}
+
+// SplitFunctionName splits "github.com/org/repo/pkg.(*Type).Method" into
+// its package path and the function name inside that package.
+func SplitFunctionName(name string) (string, string) {
+	lastSlash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[lastSlash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	dot += lastSlash + 1
+	return name[:dot], name[dot+1:]
+}
@@ -0,0 +1,157 @@
+// Package panicparse reads the output of Go programs that died of a panic or
+// fatal error, and the goroutine dumps of runtime.Stack.
+package panicparse
+
+import (
+	"bytes"
+	"strings"
+)
+
+var crashHeaders = []string{"panic: ", "fatal error: "}
+
+// Crash is the output of a program that died of a panic or fatal error.
+type Crash struct {
+	// Kind is "panic" or "fatal error".
+	Kind string
+	// Panics lists the panic values in the order they were raised, so the
+	// last one killed the program. A fatal error has one entry.
+	Panics []Panic
+	// Nested lists the crash headers printed after the first one, such as a
+	// fatal error raised while the program was dying of a panic.
+	Nested []Panic
+	// Signal is the "[signal ...]" line printed for faults.
+	Signal     string
+	Goroutines []Goroutine
+	// Truncated is set when the output ends in the middle of a goroutine.
+	Truncated bool
+	// Raw is the output from the crash header on.
+	Raw string
+}
+
+// Panic is one value of a chain of nested panics. Values printed over several
+// lines, such as errors.Join errors, keep their line breaks.
+type Panic struct {
+	// Kind is "panic" or "fatal error".
+	Kind      string
+	Value     string
+	Recovered bool
+}
+
+// Parse reads the crash in output, which may be preceded by anything the
+// program logged. It starts at the first crash header; later ones are kept in
+// Nested. It reports false when there is no crash.
+func Parse(output []byte) (*Crash, bool) {
+	start := crashStart(output)
+	if start < 0 {
+		return nil, false
+	}
+	output = output[start:]
+
+	crash := &Crash{Raw: string(output)}
+	lines := strings.Split(strings.TrimSuffix(crash.Raw, "\n"), "\n")
+	crash.Panics, crash.Signal = parseHeader(lines)
+	crash.Kind = crash.Panics[0].Kind
+	for i := 1; i < len(lines); i++ {
+		if crashKind(lines[i]) != "" {
+			panics, _ := parseHeader(lines[i:])
+			crash.Nested = append(crash.Nested, panics...)
+		}
+	}
+
+	crash.Goroutines, crash.Truncated = ParseGoroutines(output)
+	return crash, true
+}
+
+// parseHeader reads the panic values and signal printed from the crash header
+// at lines[0] up to the first goroutine.
+func parseHeader(lines []string) ([]Panic, string) {
+	var (
+		panics []Panic
+		signal string
+	)
+header:
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case i == 0:
+			kind := crashKind(line)
+			panics = append(panics, Panic{Kind: kind, Value: strings.TrimPrefix(line, kind+": ")})
+		case panics[0].Kind == "panic" && strings.HasPrefix(line, "\tpanic: "):
+			panics = append(panics, Panic{Kind: "panic", Value: strings.TrimPrefix(line, "\tpanic: ")})
+		case strings.HasPrefix(line, "\t"):
+			last := &panics[len(panics)-1]
+			last.Value += "\n" + strings.TrimPrefix(line, "\t")
+		case strings.HasPrefix(line, "[signal "):
+			signal = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+		default:
+			break header
+		}
+	}
+	for i := range panics {
+		panics[i].Value, panics[i].Recovered = cutRecovered(panics[i].Value)
+	}
+	return panics, signal
+}
+
+// crashKind returns the kind of the crash header starting line, or "".
+func crashKind(line string) string {
+	for _, header := range crashHeaders {
+		if strings.HasPrefix(line, header) {
+			return strings.TrimSuffix(header, ": ")
+		}
+	}
+	return ""
+}
+
+// Message is the value of the panic that killed the program, or the message
+// of the fatal error.
+func (c *Crash) Message() string {
+	return c.Panics[len(c.Panics)-1].Value
+}
+
+// Name is "runtime.Error" for runtime error panics and the kind otherwise.
+func (c *Crash) Name() string {
+	return panicName(c.Kind, c.Message())
+}
+
+// Title is the first line of the crash, without the earlier panics.
+func (c *Crash) Title() string {
+	message, _, _ := strings.Cut(c.Message(), "\n")
+	return c.Kind + ": " + message
+}
+
+// cutRecovered removes the " [recovered]" marker of Go 1.22 and earlier, and
+// the " [recovered, repanicked]" marker of later versions.
+func cutRecovered(value string) (string, bool) {
+	for _, marker := range []string{" [recovered]", " [recovered, repanicked]"} {
+		if trimmed, ok := strings.CutSuffix(value, marker); ok {
+			return trimmed, true
+		}
+	}
+	return value, false
+}
+
+func panicName(kind, value string) string {
+	if strings.HasPrefix(value, "runtime error: ") {
+		return "runtime.Error"
+	}
+	return kind
+}
+
+// crashStart returns the offset of the first crash header at the start of a
+// line, or -1.
+func crashStart(output []byte) int {
+	start := -1
+	for _, header := range crashHeaders {
+		i := -1
+		if bytes.HasPrefix(output, []byte(header)) {
+			i = 0
+		} else if j := bytes.Index(output, []byte("\n"+header)); j >= 0 {
+			i = j + 1
+		}
+		if i >= 0 && (start < 0 || i < start) {
+			start = i
+		}
+	}
+	return start
+}
@@ -0,0 +1,259 @@
+package panicparse
+
+import (
+	"strings"
+	"testing"
+)
+
+const nilPointerCrash = `starting worker
+panic: runtime error: invalid memory address or nil pointer dereference
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x48f1b2]
+
+goroutine 7 [running]:
+github.com/acme/billing/invoice.(*Store).Total(0x0)
+	/src/billing/invoice/store.go:42 +0x12
+github.com/acme/billing/invoice.Close(...)
+	/src/billing/invoice/close.go:18
+created by main.main in goroutine 1
+	/src/billing/main.go:30 +0x4e
+
+goroutine 1 [chan receive, 3 minutes]:
+main.main()
+	/src/billing/main.go:31 +0x5a
+exit status 2
+`
+
+func TestParse(t *testing.T) {
+	t.Run("should parse the crashing goroutine", func(t *testing.T) {
+		crash, ok := Parse([]byte(nilPointerCrash))
+		if !ok {
+			t.Fatal("expected a crash")
+		}
+
+		if crash.Kind != "panic" || crash.Name() != "runtime.Error" {
+			t.Errorf("expected a runtime error panic, got %s %s", crash.Kind, crash.Name())
+		}
+		if crash.Title() != "panic: runtime error: invalid memory address or nil pointer dereference" {
+			t.Errorf("unexpected title %s", crash.Title())
+		}
+		if crash.Signal != "signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x48f1b2" {
+			t.Errorf("unexpected signal %q", crash.Signal)
+		}
+		if !strings.HasPrefix(crash.Raw, "panic: ") {
+			t.Errorf("expected raw output to start at the header, got %q", crash.Raw)
+		}
+		if crash.Truncated {
+			t.Error("expected a complete crash")
+		}
+		if len(crash.Goroutines) != 2 {
+			t.Fatalf("expected 2 goroutines, got %d", len(crash.Goroutines))
+		}
+
+		g := crash.Goroutines[0]
+		if g.ID != 7 || g.State != "running" || len(g.Frames) != 2 {
+			t.Fatalf("unexpected goroutine %+v", g)
+		}
+		frame := g.Frames[0]
+		if frame.Function != "github.com/acme/billing/invoice.(*Store).Total" ||
+			frame.Package != "github.com/acme/billing/invoice" || frame.Name != "(*Store).Total" ||
+			frame.File != "/src/billing/invoice/store.go" || frame.Line != 42 {
+			t.Errorf("unexpected frame %+v", frame)
+		}
+		if g.Frames[1].Line != 18 {
+			t.Errorf("expected inlined frame without offset, got %+v", g.Frames[1])
+		}
+		if g.CreatedBy == nil || g.CreatedBy.Function != "main.main" || g.CreatedBy.Line != 30 || g.CreatedByID != 1 {
+			t.Errorf("unexpected creator %+v %d", g.CreatedBy, g.CreatedByID)
+		}
+		if main := crash.Goroutines[1]; main.State != "chan receive" || main.WaitMinutes != 3 || len(main.Frames) != 1 {
+			t.Errorf("expected trailing lines to be ignored, got %+v", main)
+		}
+	})
+
+	t.Run("should parse nested panics", func(t *testing.T) {
+		output := "panic: first [recovered]\n\tpanic: second\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:9 +0x1d\n"
+
+		crash, _ := Parse([]byte(output))
+
+		if len(crash.Panics) != 2 || crash.Panics[0].Value != "first" || !crash.Panics[0].Recovered {
+			t.Fatalf("unexpected panics %+v", crash.Panics)
+		}
+		if crash.Message() != "second" || crash.Panics[1].Recovered {
+			t.Errorf("expected the last panic to kill the program, got %+v", crash.Panics[1])
+		}
+	})
+
+	t.Run("should parse repanicked values", func(t *testing.T) {
+		crash, _ := Parse([]byte("panic: boom [recovered, repanicked]\n\ngoroutine 1 [running]:\n"))
+
+		if len(crash.Panics) != 1 || crash.Message() != "boom" || !crash.Panics[0].Recovered {
+			t.Errorf("unexpected panics %+v", crash.Panics)
+		}
+	})
+
+	t.Run("should keep multi-line values together", func(t *testing.T) {
+		output := "panic: open config: no such file\n\tdial db: connection refused [recovered]\n\tpanic: shutdown failed\n\ngoroutine 1 [running]:\n"
+
+		crash, _ := Parse([]byte(output))
+
+		if len(crash.Panics) != 2 || crash.Panics[0].Value != "open config: no such file\ndial db: connection refused" {
+			t.Fatalf("unexpected panics %+v", crash.Panics)
+		}
+		if crash.Title() != "panic: shutdown failed" {
+			t.Errorf("unexpected title %s", crash.Title())
+		}
+
+		crash, _ = Parse([]byte("panic: a\n\tb\n\ngoroutine 1 [running]:\n"))
+		if crash.Message() != "a\nb" || crash.Title() != "panic: a" {
+			t.Errorf("expected the title to use the first line, got %q %q", crash.Message(), crash.Title())
+		}
+	})
+
+	t.Run("should parse fatal errors", func(t *testing.T) {
+		output := "fatal error: concurrent map writes\n\ngoroutine 18 gp=0xc000007c00 m=4 mp=0xc000100008 [running, locked to thread]:\ninternal/runtime/maps.fatal({0x4b1e3a?, 0x0?})\n\t/usr/local/go/src/runtime/panic.go:1058 +0x18 fp=0xc0000 sp=0xc0000 pc=0x46b2d8\n"
+
+		crash, _ := Parse([]byte(output))
+
+		if crash.Kind != "fatal error" || crash.Name() != "fatal error" || crash.Message() != "concurrent map writes" {
+			t.Errorf("unexpected crash %s %s %s", crash.Kind, crash.Name(), crash.Message())
+		}
+		g := crash.Goroutines[0]
+		if g.ID != 18 || g.State != "running" || !g.Locked {
+			t.Errorf("unexpected goroutine %+v", g)
+		}
+		if g.Frames[0].Line != 1058 || g.Frames[0].Package != "internal/runtime/maps" {
+			t.Errorf("unexpected frame %+v", g.Frames[0])
+		}
+	})
+
+	t.Run("should start at the first crash", func(t *testing.T) {
+		output := "recovered: panic: ignored\npanic: first\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:3 +0x1\nfatal error: panic while printing panic value\n\ngoroutine 1 [running]:\n"
+
+		crash, _ := Parse([]byte(output))
+
+		if crash.Kind != "panic" || crash.Message() != "first" || !strings.HasPrefix(crash.Raw, "panic: first") {
+			t.Errorf("expected the first crash, got %s %s", crash.Kind, crash.Message())
+		}
+		if len(crash.Nested) != 1 || crash.Nested[0].Kind != "fatal error" || crash.Nested[0].Value != "panic while printing panic value" {
+			t.Fatalf("expected the later crash to be nested, got %+v", crash.Nested)
+		}
+
+		exceptions := crash.Event().Event.Exceptions
+		if len(exceptions) != 2 || exceptions[0].Message != "first" || exceptions[1].Name != "fatal error" || exceptions[1].Parent != 0 {
+			t.Errorf("expected the later crash to be chained as a cause, got %+v", exceptions)
+		}
+	})
+
+	t.Run("should keep what was read from truncated output", func(t *testing.T) {
+		for _, cut := range []string{"\t/src/billing/invoice/sto", "github.com/acme/billing/invoice.Close(...)\n"} {
+			end := strings.Index(nilPointerCrash, cut) + len(cut)
+			crash, ok := Parse([]byte(nilPointerCrash[:end]))
+			if !ok || !crash.Truncated {
+				t.Fatalf("expected a truncated crash for %q", cut)
+			}
+			if len(crash.Goroutines) != 1 {
+				t.Fatalf("expected 1 goroutine, got %d", len(crash.Goroutines))
+			}
+		}
+
+		crash, _ := Parse([]byte("panic: boo"))
+		if crash.Message() != "boo" || !crash.Truncated {
+			t.Errorf("expected truncated header, got %+v", crash)
+		}
+	})
+
+	t.Run("should report output without a crash", func(t *testing.T) {
+		for _, output := range []string{"", "exit status 1\n", "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:3 +0x1\n"} {
+			if _, ok := Parse([]byte(output)); ok {
+				t.Errorf("expected no crash in %q", output)
+			}
+		}
+	})
+}
+
+func TestParseGoroutines(t *testing.T) {
+	t.Run("should parse runtime.Stack output", func(t *testing.T) {
+		dump := "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:3 +0x1\n\ngoroutine 9 [select]:\nmain.loop(...)\n\t/app/loop.go:12\n...additional frames elided...\ncreated by main.start\n\t/app/main.go:7 +0x2\n"
+
+		goroutines, truncated := ParseGoroutines([]byte(dump))
+
+		if truncated || len(goroutines) != 2 {
+			t.Fatalf("expected 2 complete goroutines, got %d %v", len(goroutines), truncated)
+		}
+		if g := goroutines[1]; !g.FramesElided || g.CreatedBy == nil || g.CreatedByID != 0 {
+			t.Errorf("unexpected goroutine %+v", g)
+		}
+	})
+}
+
+func TestCrashEvent(t *testing.T) {
+	t.Run("should build a fatal event", func(t *testing.T) {
+		crash, _ := Parse([]byte(nilPointerCrash))
+
+		event := crash.Event()
+
+		if event.Level != "FATAL" || event.Title != crash.Title() || event.Event.Name != "runtime.Error" {
+			t.Errorf("unexpected event %s %s %s", event.Level, event.Title, event.Event.Name)
+		}
+		if event.Event.Mechanism == nil || event.Event.Mechanism.Type != "crash" || event.Event.Mechanism.Handled {
+			t.Errorf("unexpected mechanism %+v", event.Event.Mechanism)
+		}
+		if event.Context.Culprit != "github.com/acme/billing/invoice.(*Store).Total:42" {
+			t.Errorf("unexpected culprit %s", event.Context.Culprit)
+		}
+		if len(event.Event.Frames) != 2 || event.Event.Frames[0].AbsPath != "/src/billing/invoice/store.go" {
+			t.Errorf("unexpected frames %+v", event.Event.Frames)
+		}
+		if event.Event.Extra["signal"] != crash.Signal {
+			t.Errorf("expected the signal, got %v", event.Event.Extra)
+		}
+		if event.Goroutines == nil || event.Goroutines.Total != 2 || len(event.Goroutines.Groups) != 2 {
+			t.Errorf("unexpected goroutines %+v", event.Goroutines)
+		}
+	})
+
+	t.Run("should skip the panic builtin in the culprit", func(t *testing.T) {
+		output := "panic: boom\n\ngoroutine 1 [running]:\npanic({0x4a2f40?, 0x4f1c30?})\n\t/usr/local/go/src/runtime/panic.go:785 +0x132\nmain.main()\n\t/app/main.go:9 +0x1d\n"
+		crash, _ := Parse([]byte(output))
+
+		if culprit := crash.Event().Context.Culprit; culprit != "main.main:9" {
+			t.Errorf("unexpected culprit %s", culprit)
+		}
+	})
+
+	t.Run("should chain nested panics", func(t *testing.T) {
+		crash, _ := Parse([]byte("panic: first [recovered]\n\tpanic: runtime error: slice bounds out of range\n\ngoroutine 1 [running]:\n"))
+
+		exceptions := crash.Event().Event.Exceptions
+
+		if len(exceptions) != 2 {
+			t.Fatalf("expected 2 exceptions, got %+v", exceptions)
+		}
+		if exceptions[0].Name != "runtime.Error" || exceptions[0].Parent != -1 {
+			t.Errorf("expected the fatal panic first, got %+v", exceptions[0])
+		}
+		if exceptions[1].Message != "first" || exceptions[1].Parent != 0 {
+			t.Errorf("expected the recovered panic second, got %+v", exceptions[1])
+		}
+	})
+}
+
+func TestGroup(t *testing.T) {
+	t.Run("should group identical goroutines", func(t *testing.T) {
+		var dump strings.Builder
+		for id := 1; id <= 12; id++ {
+			dump.WriteString("goroutine " + strings.Repeat("1", id) + " [chan receive]:\nmain.worker()\n\t/app/worker.go:5 +0x1\n\n")
+		}
+		dump.WriteString("goroutine 2 [running]:\nmain.main()\n\t/app/main.go:3 +0x1\n")
+		goroutines, _ := ParseGoroutines([]byte(dump.String()))
+
+		grouped := Group(goroutines, false)
+
+		if grouped.Total != 13 || len(grouped.Groups) != 2 {
+			t.Fatalf("unexpected dump %+v", grouped)
+		}
+		if workers := grouped.Groups[0]; workers.Count != 12 || len(workers.IDs) != 10 || workers.State != "chan receive" {
+			t.Errorf("unexpected worker group %+v", workers)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const defaultMemStatsMaxAge = 10 * time.Second
+
+// processStart approximates the process start time with the moment the SDK
+// was initialised.
+var processStart = time.Now()
+
+// runtimeCollector builds the runtime context of events. runtime.ReadMemStats
+// stops the world, so memory stats are cached for maxAge and only read fresh
+// for fatal events. A negative maxAge leaves them out.
+type runtimeCollector struct {
+	maxAge time.Duration
+
+	mu     sync.Mutex
+	memory *types.MemoryStats
+	readAt time.Time
+}
+
+func newRuntimeCollector(maxAge time.Duration) *runtimeCollector {
+	if maxAge == 0 {
+		maxAge = defaultMemStatsMaxAge
+	}
+	return &runtimeCollector{maxAge: maxAge}
+}
+
+func (rc *runtimeCollector) collect(fresh bool) *types.RuntimeContext {
+	return &types.RuntimeContext{
+		GoVersion:     runtime.Version(),
+		GOARCH:        runtime.GOARCH,
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		NumCPU:        runtime.NumCPU(),
+		NumGoroutine:  runtime.NumGoroutine(),
+		PID:           os.Getpid(),
+		UptimeSeconds: time.Since(processStart).Seconds(),
+		Memory:        rc.memoryStats(fresh),
+	}
+}
+
+func (rc *runtimeCollector) memoryStats(fresh bool) *types.MemoryStats {
+	if rc.maxAge < 0 {
+		return nil
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if !fresh && rc.memory != nil && time.Since(rc.readAt) < rc.maxAge {
+		memory := *rc.memory
+		return &memory
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	rc.memory = &types.MemoryStats{
+		Alloc:          stats.Alloc,
+		TotalAlloc:     stats.TotalAlloc,
+		Sys:            stats.Sys,
+		HeapAlloc:      stats.HeapAlloc,
+		HeapInuse:      stats.HeapInuse,
+		HeapObjects:    stats.HeapObjects,
+		StackInuse:     stats.StackInuse,
+		NumGC:          stats.NumGC,
+		GCPauseTotalNs: stats.PauseTotalNs,
+		LastGCPauseNs:  stats.PauseNs[(stats.NumGC+255)%256],
+	}
+	rc.readAt = time.Now()
+
+	memory := *rc.memory
+	return &memory
+}
@@ -0,0 +1,313 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const (
+	defaultMaxDepth        = 6
+	defaultMaxItems        = 100
+	defaultMaxStringLength = 8192
+
+	ellipsis = "…"
+)
+
+// sanitizer turns arbitrary metadata into values encoding/json can always
+// marshal: strings, numbers, bools, nil, map[string]any and []any. It never
+// fails; values it cannot represent are replaced with a descriptive string.
+// Truncation markers count towards the limits, so sanitizing twice is a no-op.
+type sanitizer struct {
+	limits   types.MetadataLimits
+	visiting map[visit]bool
+}
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func withDefaultLimits(limits types.MetadataLimits) types.MetadataLimits {
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = defaultMaxDepth
+	}
+	if limits.MaxItems <= 0 {
+		limits.MaxItems = defaultMaxItems
+	}
+	if limits.MaxStringLength <= 0 {
+		limits.MaxStringLength = defaultMaxStringLength
+	}
+	return limits
+}
+
+// SanitizeExtra returns a JSON-safe copy of extra within limits.
+func SanitizeExtra(extra map[string]any, limits types.MetadataLimits) map[string]any {
+	if extra == nil {
+		return nil
+	}
+
+	s := &sanitizer{
+		limits:   withDefaultLimits(limits),
+		visiting: make(map[visit]bool),
+	}
+	sanitized, _ := s.mapValue(reflect.ValueOf(extra), 0).(map[string]any)
+	return sanitized
+}
+
+func (s *sanitizer) value(value any, depth int) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return s.string(v)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		return s.float(float64(v))
+	case float64:
+		return s.float(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return s.float(f)
+		}
+		return s.string(v.String())
+	case []byte:
+		return s.string(string(v))
+	}
+
+	rv := reflect.ValueOf(value)
+	if (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case *time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		return s.call(v.Error)
+	case json.Marshaler:
+		return s.marshaler(v, depth)
+	case fmt.Stringer:
+		return s.call(v.String)
+	}
+
+	return s.reflectValue(rv, depth)
+}
+
+func (s *sanitizer) reflectValue(rv reflect.Value, depth int) any {
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return s.float(rv.Float())
+	case reflect.String:
+		return s.string(rv.String())
+	case reflect.Pointer:
+		return s.guard(rv, func() any {
+			return s.value(rv.Elem().Interface(), depth)
+		})
+	case reflect.Interface:
+		return s.value(rv.Elem().Interface(), depth)
+	case reflect.Map:
+		return s.guard(rv, func() any {
+			return s.mapValue(rv, depth)
+		})
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil
+		}
+		return s.guard(rv, func() any {
+			return s.sliceValue(rv, depth)
+		})
+	case reflect.Array:
+		return s.sliceValue(rv, depth)
+	case reflect.Struct:
+		return s.structValue(rv, depth)
+	default:
+		// Channels, functions, complex numbers and unsafe pointers.
+		return fmt.Sprintf("[%s]", rv.Type())
+	}
+}
+
+// guard detects reference cycles on the current path.
+func (s *sanitizer) guard(rv reflect.Value, f func() any) any {
+	key := visit{ptr: rv.Pointer(), typ: rv.Type()}
+	if s.visiting[key] {
+		return "[Circular]"
+	}
+	s.visiting[key] = true
+	defer delete(s.visiting, key)
+	return f()
+}
+
+func (s *sanitizer) mapValue(rv reflect.Value, depth int) any {
+	if rv.IsNil() {
+		return nil
+	}
+	if depth >= s.limits.MaxDepth {
+		return "[MaxDepth]"
+	}
+
+	keys := make([]string, 0, rv.Len())
+	values := make(map[string]reflect.Value, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := s.mapKey(iter.Key())
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]any, len(keys))
+	for i, key := range keys {
+		if len(keys) > s.limits.MaxItems && i == s.limits.MaxItems-1 {
+			result["[Truncated]"] = s.string(fmt.Sprintf("%d more keys", len(keys)-i))
+			break
+		}
+		result[key] = s.value(values[key].Interface(), depth+1)
+	}
+	return result
+}
+
+func (s *sanitizer) mapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return strings.ToValidUTF8(key.String(), "\uFFFD")
+	}
+	return strings.ToValidUTF8(fmt.Sprint(key.Interface()), "\uFFFD")
+}
+
+func (s *sanitizer) sliceValue(rv reflect.Value, depth int) any {
+	if depth >= s.limits.MaxDepth {
+		return "[MaxDepth]"
+	}
+
+	n := rv.Len()
+	result := make([]any, 0, min(n, s.limits.MaxItems))
+	for i := 0; i < n; i++ {
+		if n > s.limits.MaxItems && i == s.limits.MaxItems-1 {
+			result = append(result, s.string(fmt.Sprintf("[Truncated: %d more items]", n-i)))
+			break
+		}
+		result = append(result, s.value(rv.Index(i).Interface(), depth+1))
+	}
+	return result
+}
+
+// structValue follows the encoding/json field names and skips unexported
+// fields and fields tagged "-".
+func (s *sanitizer) structValue(rv reflect.Value, depth int) any {
+	if depth >= s.limits.MaxDepth {
+		return "[MaxDepth]"
+	}
+
+	t := rv.Type()
+	var names []string
+	var fields []reflect.Value
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		names = append(names, name)
+		fields = append(fields, rv.Field(i))
+	}
+
+	result := make(map[string]any, len(names))
+	for i, name := range names {
+		if len(names) > s.limits.MaxItems && i == s.limits.MaxItems-1 {
+			result["[Truncated]"] = s.string(fmt.Sprintf("%d more fields", len(names)-i))
+			break
+		}
+		result[name] = s.value(fields[i].Interface(), depth+1)
+	}
+	return result
+}
+
+func (s *sanitizer) marshaler(v json.Marshaler, depth int) (result any) {
+	defer func() {
+		if recover() != nil {
+			result = fmt.Sprintf("[%T]", v)
+		}
+	}()
+
+	data, err := v.MarshalJSON()
+	if err != nil {
+		return fmt.Sprintf("[%T]", v)
+	}
+
+	var decoded any
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return fmt.Sprintf("[%T]", v)
+	}
+	return s.value(decoded, depth)
+}
+
+// call runs a user String or Error method, which may panic on values it does
+// not expect.
+func (s *sanitizer) call(f func() string) (result any) {
+	defer func() {
+		if recover() != nil {
+			result = "[PANIC]"
+		}
+	}()
+	return s.string(f())
+}
+
+func (s *sanitizer) float(v float64) any {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return v
+}
+
+// string replaces invalid UTF-8 and truncates to MaxStringLength bytes,
+// ellipsis included, on a rune boundary.
+func (s *sanitizer) string(v string) string {
+	v = strings.ToValidUTF8(v, "\uFFFD")
+	if len(v) <= s.limits.MaxStringLength {
+		return v
+	}
+
+	cut := max(s.limits.MaxStringLength-len(ellipsis), 0)
+	for cut > 0 && !utf8.RuneStart(v[cut]) {
+		cut--
+	}
+	return v[:cut] + ellipsis
+}
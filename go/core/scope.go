@@ -0,0 +1,381 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+type scopeContextKey struct{}
+
+type Scope struct {
+	mu          sync.RWMutex
+	tags        map[string]string
+	extra       map[string]any
+	user        *types.User
+	level       *types.EventLevel
+	fingerprint []string
+	breadcrumbs *BreadcrumbBuffer
+	processors  []scopeProcessor
+}
+
+// scopeProcessor keeps the id a processor was registered with, so a scope
+// merged with its own clone runs it once.
+type scopeProcessor struct {
+	id        uint64
+	processor types.EventProcessor
+}
+
+var processorIDs atomic.Uint64
+
+func NewScope() *Scope {
+	return &Scope{
+		tags:  make(map[string]string),
+		extra: make(map[string]any),
+	}
+}
+
+func ContextWithScope(ctx context.Context, scope *Scope) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, scopeContextKey{}, scope)
+}
+
+func ScopeFromContext(ctx context.Context) *Scope {
+	if ctx == nil {
+		return nil
+	}
+	scope, _ := ctx.Value(scopeContextKey{}).(*Scope)
+	return scope
+}
+
+func (s *Scope) SetTag(key, value string) *Scope {
+	s.mu.Lock()
+	s.tags[key] = value
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) SetTags(tags map[string]string) *Scope {
+	s.mu.Lock()
+	for key, value := range tags {
+		s.tags[key] = value
+	}
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) SetExtra(key string, value any) *Scope {
+	s.mu.Lock()
+	s.extra[key] = value
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) SetExtras(extra map[string]string) *Scope {
+	s.mu.Lock()
+	for key, value := range extra {
+		s.extra[key] = value
+	}
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) SetExtraValues(extra map[string]any) *Scope {
+	s.mu.Lock()
+	for key, value := range extra {
+		s.extra[key] = value
+	}
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) SetUser(user types.User) *Scope {
+	s.mu.Lock()
+	s.user = &user
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) SetLevel(level types.EventLevel) *Scope {
+	s.mu.Lock()
+	s.level = &level
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) SetFingerprint(fingerprint []string) *Scope {
+	s.mu.Lock()
+	s.fingerprint = append([]string(nil), fingerprint...)
+	s.mu.Unlock()
+	return s
+}
+
+// AddBreadcrumb records a breadcrumb, keeping at most limit entries. A limit of
+// zero or less disables breadcrumbs for the scope.
+func (s *Scope) AddBreadcrumb(breadcrumb types.Breadcrumb, limit int) *Scope {
+	if limit <= 0 {
+		return s
+	}
+	if breadcrumb.Timestamp.IsZero() {
+		breadcrumb.Timestamp = time.Now().UTC()
+	}
+	if breadcrumb.Level == "" {
+		breadcrumb.Level = types.LevelInfo
+	}
+
+	s.mu.Lock()
+	if s.breadcrumbs == nil {
+		s.breadcrumbs = NewBreadcrumbBuffer(limit)
+	} else {
+		s.breadcrumbs.Resize(limit)
+	}
+	s.breadcrumbs.Add(breadcrumb)
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) Breadcrumbs() []types.Breadcrumb {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.breadcrumbs == nil {
+		return nil
+	}
+	return s.breadcrumbs.Items()
+}
+
+func (s *Scope) ClearBreadcrumbs() *Scope {
+	s.mu.Lock()
+	s.breadcrumbs = nil
+	s.mu.Unlock()
+	return s
+}
+
+// AddEventProcessor registers a processor run on every event captured with
+// this scope, after the global and client processors.
+func (s *Scope) AddEventProcessor(processor types.EventProcessor) *Scope {
+	s.mu.Lock()
+	s.processors = append(s.processors, scopeProcessor{id: processorIDs.Add(1), processor: processor})
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) EventProcessors() []types.EventProcessor {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	processors := make([]types.EventProcessor, 0, len(s.processors))
+	for _, entry := range s.processors {
+		processors = append(processors, entry.processor)
+	}
+	return processors
+}
+
+// ScopeEventProcessors returns the processors of scopes ordered from highest
+// to lowest priority, lowest priority first. A processor shared by a scope and
+// its clone is listed once.
+func ScopeEventProcessors(scopes ...*Scope) []types.EventProcessor {
+	merged := &Scope{}
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if scopes[i] == nil {
+			continue
+		}
+		scopes[i].mu.RLock()
+		merged.addProcessors(scopes[i].processors)
+		scopes[i].mu.RUnlock()
+	}
+	return merged.EventProcessors()
+}
+
+func (s *Scope) RemoveTag(key string) *Scope {
+	s.mu.Lock()
+	delete(s.tags, key)
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) RemoveExtra(key string) *Scope {
+	s.mu.Lock()
+	delete(s.extra, key)
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) Clear() *Scope {
+	s.mu.Lock()
+	s.tags = make(map[string]string)
+	s.extra = make(map[string]any)
+	s.user = nil
+	s.level = nil
+	s.fingerprint = nil
+	s.breadcrumbs = nil
+	s.processors = nil
+	s.mu.Unlock()
+	return s
+}
+
+func (s *Scope) Clone() *Scope {
+	clone := NewScope()
+	clone.overlay(s)
+	return clone
+}
+
+// overlay copies every value set on other into s, replacing existing ones.
+// Processors and breadcrumbs already in s, as when other is a clone of s, are
+// not added twice.
+func (s *Scope) overlay(other *Scope) {
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+
+	for key, value := range other.tags {
+		s.tags[key] = value
+	}
+	for key, value := range other.extra {
+		s.extra[key] = value
+	}
+	if other.user != nil {
+		user := *other.user
+		s.user = &user
+	}
+	if other.level != nil {
+		level := *other.level
+		s.level = &level
+	}
+	if len(other.fingerprint) > 0 {
+		s.fingerprint = append([]string(nil), other.fingerprint...)
+	}
+	if other.breadcrumbs != nil && other.breadcrumbs.Len() > 0 {
+		s.mergeBreadcrumbs(other.breadcrumbs)
+	}
+	s.addProcessors(other.processors)
+}
+
+func (s *Scope) addProcessors(processors []scopeProcessor) {
+next:
+	for _, entry := range processors {
+		for _, existing := range s.processors {
+			if existing.id == entry.id {
+				continue next
+			}
+		}
+		s.processors = append(s.processors, entry)
+	}
+}
+
+func (s *Scope) mergeBreadcrumbs(other *BreadcrumbBuffer) {
+	if s.breadcrumbs == nil {
+		s.breadcrumbs = NewBreadcrumbBuffer(other.Limit())
+		for _, breadcrumb := range other.Items() {
+			s.breadcrumbs.Add(breadcrumb)
+		}
+		return
+	}
+
+	items := s.breadcrumbs.Items()
+	existing := len(items)
+	for _, breadcrumb := range other.Items() {
+		if !containsBreadcrumb(items[:existing], breadcrumb) {
+			items = append(items, breadcrumb)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Timestamp.Before(items[j].Timestamp)
+	})
+
+	s.breadcrumbs = NewBreadcrumbBuffer(max(s.breadcrumbs.Limit(), other.Limit()))
+	for _, breadcrumb := range items {
+		s.breadcrumbs.Add(breadcrumb)
+	}
+}
+
+func containsBreadcrumb(breadcrumbs []types.Breadcrumb, breadcrumb types.Breadcrumb) bool {
+	for _, item := range breadcrumbs {
+		if item.Timestamp.Equal(breadcrumb.Timestamp) && item.Category == breadcrumb.Category &&
+			item.Message == breadcrumb.Message && item.Level == breadcrumb.Level && maps.Equal(item.Data, breadcrumb.Data) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeScopes flattens scopes ordered from highest to lowest priority into a
+// single scope. It returns nil when there is nothing to apply.
+func mergeScopes(scopes ...*Scope) *Scope {
+	var merged *Scope
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if scopes[i] == nil {
+			continue
+		}
+		if merged == nil {
+			merged = NewScope()
+		}
+		merged.overlay(scopes[i])
+	}
+	return merged
+}
+
+// ApplyToEvent fills the event with the scope data. Values already present on
+// the event win, so call-site metadata always overrides the scope. The scope
+// level only applies to events captured without a level.
+func (s *Scope) ApplyToEvent(event *types.EventIssue) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.extra) > 0 {
+		if event.Context.Extra == nil {
+			event.Context.Extra = make(map[string]any, len(s.extra))
+		}
+		for key, value := range s.extra {
+			if _, ok := event.Context.Extra[key]; !ok {
+				event.Context.Extra[key] = value
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(s.tags))
+	for key := range s.tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !hasTagKey(event.Context.Tags, key) {
+			event.Context.Tags = append(event.Context.Tags, fmt.Sprintf("%s:%s", key, s.tags[key]))
+		}
+	}
+
+	if event.Context.User == nil && s.user != nil {
+		user := *s.user
+		event.Context.User = &user
+	}
+
+	if event.Level == "" && s.level != nil {
+		event.Level = string(*s.level)
+	}
+
+	if len(event.Fingerprint) == 0 && len(s.fingerprint) > 0 {
+		event.Fingerprint = append([]string(nil), s.fingerprint...)
+	}
+
+	if len(event.Breadcrumbs) == 0 && s.breadcrumbs != nil && s.breadcrumbs.Len() > 0 {
+		event.Breadcrumbs = s.breadcrumbs.Items()
+	}
+}
+
+func hasTagKey(tags []string, key string) bool {
+	prefix := key + ":"
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,432 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const Filtered = "[Filtered]"
+
+// maxDenyWordLen is the length up to which denied keys must match a whole
+// word of a key instead of any part of it.
+const maxDenyWordLen = 3
+
+var defaultDenyKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"cookie",
+	"apikey",
+	"privatekey",
+	"creditcard",
+	"cardnumber",
+	"cvv",
+	"ssn",
+}
+
+var defaultScrubRules = []types.ScrubRule{
+	{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	},
+	{
+		Name:        "bearer",
+		Pattern:     regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9._~+/-]+=*`),
+		Replacement: "$1 " + Filtered,
+	},
+	{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	{
+		Name:    "ipv4",
+		Pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`),
+	},
+	{
+		Name:    "ipv6",
+		Pattern: regexp.MustCompile(`(?i)\b(?:[0-9a-f]{1,4}:){7}[0-9a-f]{1,4}\b`),
+	},
+	{
+		Name:     "card",
+		Pattern:  regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Validate: luhn,
+	},
+}
+
+// Scrubber redacts secrets and personal data from events: values of
+// denylisted keys are replaced entirely, and every other string is matched
+// against the redaction rules.
+type Scrubber struct {
+	denyKeys  []string
+	denyWords map[string]bool
+	allowKeys map[string]bool
+	allowlist bool
+	rules     []types.ScrubRule
+	codeRules []types.ScrubRule
+}
+
+// NewScrubber returns nil when scrubbing is disabled. A nil Scrubber leaves
+// events untouched.
+func NewScrubber(options types.ScrubOptions) *Scrubber {
+	if options.Disabled {
+		return nil
+	}
+
+	scrubber := &Scrubber{
+		denyWords: make(map[string]bool),
+		allowKeys: make(map[string]bool, len(options.AllowKeys)),
+		allowlist: options.AllowlistMode,
+	}
+	for _, key := range append(append([]string(nil), defaultDenyKeys...), options.DenyKeys...) {
+		normalized := normalizeKey(key)
+		if len(normalized) <= maxDenyWordLen {
+			scrubber.denyWords[normalized] = true
+			continue
+		}
+		scrubber.denyKeys = append(scrubber.denyKeys, normalized)
+	}
+	for _, key := range options.AllowKeys {
+		scrubber.allowKeys[normalizeKey(key)] = true
+	}
+	if !options.DisableDefaultRules {
+		scrubber.rules = append(scrubber.rules, defaultScrubRules...)
+		for _, rule := range defaultScrubRules {
+			// Goroutine ids, addresses and literals in stacks and source lines
+			// pass the Luhn check too often.
+			if rule.Name != "card" {
+				scrubber.codeRules = append(scrubber.codeRules, rule)
+			}
+		}
+	}
+	scrubber.rules = append(scrubber.rules, options.Rules...)
+	scrubber.codeRules = append(scrubber.codeRules, options.Rules...)
+
+	return scrubber
+}
+
+// Scrub redacts event in place and adds the number of redactions to
+// event.Redactions.
+func (s *Scrubber) Scrub(event *types.EventIssue) int {
+	if s == nil {
+		return 0
+	}
+
+	count := 0
+	scrubValue := func(value *string) {
+		var n int
+		*value, n = s.ScrubString(*value)
+		count += n
+	}
+
+	scrubValue(&event.Title)
+	scrubValue(&event.Event.Message)
+	for i := range event.Event.Exceptions {
+		scrubValue(&event.Event.Exceptions[i].Message)
+	}
+	// Crash output carries panic values, and source lines may hold literals.
+	stack, n := s.scrub(s.codeRules, event.Event.Stack)
+	event.Event.Stack = stack
+	count += n
+	scrubValue(&event.Context.Culprit)
+	count += s.scrubFrames(event.Event.Frames)
+	if event.Goroutines != nil {
+		for i := range event.Goroutines.Groups {
+			group := &event.Goroutines.Groups[i]
+			count += s.scrubFrames(group.Frames)
+			if group.CreatedBy != nil {
+				count += s.scrubFrame(group.CreatedBy)
+			}
+		}
+	}
+	count += s.scrubMap(event.Event.Extra)
+	count += s.scrubValues(event.Context.Extra)
+	count += s.scrubUser(event.Context.User)
+
+	for i, tag := range event.Context.Tags {
+		key, value, ok := strings.Cut(tag, ":")
+		if !ok {
+			continue
+		}
+		// Tags are mostly set by the SDK, so the allowlist does not apply.
+		scrubbed, n := s.scrubKeyValue(key, value, false)
+		event.Context.Tags[i] = key + ":" + scrubbed
+		count += n
+	}
+
+	for i := range event.Breadcrumbs {
+		breadcrumb := &event.Breadcrumbs[i]
+		scrubValue(&breadcrumb.Message)
+		breadcrumb.Data = copyExtra(breadcrumb.Data)
+		count += s.scrubMap(breadcrumb.Data)
+	}
+
+	event.Redactions += count
+	return count
+}
+
+// ScrubString applies the redaction rules to value.
+func (s *Scrubber) ScrubString(value string) (string, int) {
+	if s == nil {
+		return value, 0
+	}
+	return s.scrub(s.rules, value)
+}
+
+func (s *Scrubber) scrub(rules []types.ScrubRule, value string) (string, int) {
+	if value == "" {
+		return value, 0
+	}
+
+	count := 0
+	for _, rule := range rules {
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = Filtered
+		}
+		value = rule.Pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if rule.Validate != nil && !rule.Validate(match) {
+				return match
+			}
+			count++
+			return rule.Pattern.ReplaceAllString(match, replacement)
+		})
+	}
+	return value, count
+}
+
+func (s *Scrubber) scrubFrames(frames []types.StackFrame) int {
+	count := 0
+	for i := range frames {
+		count += s.scrubFrame(&frames[i])
+	}
+	return count
+}
+
+func (s *Scrubber) scrubFrame(frame *types.StackFrame) int {
+	count := 0
+	scrubValue := func(value *string) {
+		var n int
+		*value, n = s.scrub(s.codeRules, *value)
+		count += n
+	}
+
+	for i := range frame.PreContext {
+		scrubValue(&frame.PreContext[i])
+	}
+	scrubValue(&frame.ContextLine)
+	for i := range frame.PostContext {
+		scrubValue(&frame.PostContext[i])
+	}
+	return count
+}
+
+func (s *Scrubber) scrubMap(values map[string]string) int {
+	count := 0
+	for key, value := range values {
+		scrubbed, n := s.scrubKeyValue(key, value, s.allowlist)
+		if n > 0 {
+			values[key] = scrubbed
+			count += n
+		}
+	}
+	return count
+}
+
+// scrubUser applies the key rules to the user fields by their JSON names.
+// Fields filtered by key are dropped rather than replaced, so a denied id does
+// not merge every user into one.
+func (s *Scrubber) scrubUser(user *types.User) int {
+	if user == nil {
+		return 0
+	}
+
+	count := 0
+	fields := []struct {
+		key   string
+		value *string
+	}{
+		{"id", &user.ID},
+		{"username", &user.Username},
+		{"email", &user.Email},
+		{"ip_address", &user.IPAddress},
+		{"segment", &user.Segment},
+	}
+	for _, field := range fields {
+		if *field.value == "" {
+			continue
+		}
+		normalized := normalizeKey(field.key)
+		if s.allowKeys[normalized] {
+			continue
+		}
+		if s.allowlist || s.deniedKey(field.key, normalized) {
+			*field.value = ""
+			count++
+			continue
+		}
+		var n int
+		*field.value, n = s.ScrubString(*field.value)
+		count += n
+	}
+	count += s.scrubValues(user.Data)
+	return count
+}
+
+// scrubValues applies the key rules at every level of nested metadata. An
+// allowed key leaves its whole value untouched.
+func (s *Scrubber) scrubValues(values map[string]any) int {
+	count := 0
+	for key, value := range values {
+		normalized := normalizeKey(key)
+		if s.allowKeys[normalized] {
+			continue
+		}
+		if s.allowlist || s.deniedKey(key, normalized) {
+			if value != Filtered {
+				values[key] = Filtered
+				count++
+			}
+			continue
+		}
+		var n int
+		values[key], n = s.scrubValue(value)
+		count += n
+	}
+	return count
+}
+
+func (s *Scrubber) scrubValue(value any) (any, int) {
+	switch v := value.(type) {
+	case string:
+		return s.ScrubString(v)
+	case map[string]any:
+		return v, s.scrubValues(v)
+	case []any:
+		count := 0
+		for i := range v {
+			var n int
+			v[i], n = s.scrubValue(v[i])
+			count += n
+		}
+		return v, count
+	}
+	return value, 0
+}
+
+func (s *Scrubber) scrubKeyValue(key, value string, allowlist bool) (string, int) {
+	normalized := normalizeKey(key)
+	if s.allowKeys[normalized] {
+		return value, 0
+	}
+	if allowlist || s.deniedKey(key, normalized) {
+		if value == Filtered {
+			return value, 0
+		}
+		return Filtered, 1
+	}
+	return s.ScrubString(value)
+}
+
+// deniedKey matches long denied keys anywhere in the normalized key, and
+// short ones such as "ssn" only as a whole word of key, so "classname" and
+// "business" are kept.
+func (s *Scrubber) deniedKey(key, normalized string) bool {
+	for _, denied := range s.denyKeys {
+		if strings.Contains(normalized, denied) {
+			return true
+		}
+	}
+	if len(s.denyWords) > 0 {
+		for _, word := range keyWords(key) {
+			if s.denyWords[word] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// keyWords splits key at separators, case changes and digits, so "user_ssn",
+// "userSSN", "SSNLast4" and "cvv2" all hold a denied word.
+func keyWords(key string) []string {
+	var words []string
+	runes := []rune(key)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+			continue
+		}
+		if start >= 0 && wordBoundary(runes, i) {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// wordBoundary reports whether a word starts at runes[i], as in "userId",
+// "SSNLast" or "cvv2".
+func wordBoundary(runes []rune, i int) bool {
+	prev, r := runes[i-1], runes[i]
+	if unicode.IsDigit(prev) != unicode.IsDigit(r) {
+		return true
+	}
+	if !unicode.IsUpper(r) {
+		return false
+	}
+	return !unicode.IsUpper(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))
+}
+
+// normalizeKey lowercases key and drops separators so "X-Api-Key",
+// "api_key" and "apiKey" all match "apikey".
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', '.', ' ':
+			return -1
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, key)
+}
+
+// luhn reports whether the digits of value pass the Luhn checksum used by
+// payment card numbers.
+func luhn(value string) bool {
+	sum, digits := 0, 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && sum%10 == 0
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"os"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const (
+	defaultSourceCacheBytes = 4 << 20
+	maxSourceLineLength     = 256
+)
+
+// sourceCache keeps the lines of recently read source files, evicting the
+// least recently used ones beyond maxBytes. Files that cannot be read are
+// remembered too, so production binaries without sources only pay once.
+type sourceCache struct {
+	lines    int
+	maxBytes int
+
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type sourceFile struct {
+	path  string
+	lines []string
+	size  int
+}
+
+func newSourceCache(lines, maxBytes int) *sourceCache {
+	if maxBytes <= 0 {
+		maxBytes = defaultSourceCacheBytes
+	}
+	return &sourceCache{
+		lines:    lines,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// apply sets the context lines of frame, if its file is readable.
+func (sc *sourceCache) apply(frame *types.StackFrame) {
+	lines := sc.file(frame.AbsPath)
+	index := frame.Line - 1
+	if index < 0 || index >= len(lines) {
+		return
+	}
+
+	start := max(index-sc.lines, 0)
+	end := min(index+sc.lines+1, len(lines))
+	frame.PreContext = append([]string(nil), lines[start:index]...)
+	frame.ContextLine = lines[index]
+	frame.PostContext = append([]string(nil), lines[index+1:end]...)
+}
+
+func (sc *sourceCache) file(path string) []string {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if element, ok := sc.entries[path]; ok {
+		sc.order.MoveToFront(element)
+		return element.Value.(*sourceFile).lines
+	}
+
+	file := &sourceFile{path: path, size: len(path)}
+	if data, ok := readSource(path, sc.maxBytes); ok {
+		file.lines = splitLines(data)
+		file.size += len(data)
+	}
+
+	sc.entries[path] = sc.order.PushFront(file)
+	sc.size += file.size
+	for sc.size > sc.maxBytes && sc.order.Len() > 1 {
+		oldest := sc.order.Back()
+		evicted := sc.order.Remove(oldest).(*sourceFile)
+		delete(sc.entries, evicted.path)
+		sc.size -= evicted.size
+	}
+	return file.lines
+}
+
+// readSource reads the file at path unless it is larger than maxBytes, which
+// is checked before reading so large files are never loaded.
+func readSource(path string, maxBytes int) ([]byte, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Size() > int64(maxBytes) {
+		return nil, false
+	}
+	// The file may have grown since Stat.
+	data, err := io.ReadAll(io.LimitReader(f, int64(maxBytes)+1))
+	if err != nil || len(data) > maxBytes {
+		return nil, false
+	}
+	return data, true
+}
+
+func splitLines(data []byte) []string {
+	raw := bytes.Split(data, []byte("\n"))
+	lines := make([]string, len(raw))
+	for i, line := range raw {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(line) > maxSourceLineLength {
+			cut := maxSourceLineLength
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			line = append(line[:cut:cut], ellipsis...)
+		}
+		lines[i] = string(line)
+	}
+	return lines
+}
@@ -0,0 +1,198 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/royaltics/tracker-go/core/panicparse"
+	"github.com/royaltics/tracker-go/types"
+)
+
+const maxStackFrames = 128
+
+// callersTracer is implemented by errors that record the program counters of
+// the place they were created, e.g. github.com/go-errors/errors.
+type callersTracer interface {
+	Callers() []uintptr
+}
+
+// callers returns the program counters of the calling goroutine, skipping
+// the given number of frames above the caller of callers.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
+
+// errorCallers returns the creation stack recorded by err or by one of the
+// errors on the way to its root cause, preferring the innermost one since it
+// is closest to where the failure happened.
+func errorCallers(err error, maxDepth int) []uintptr {
+	var found []uintptr
+	for depth := 0; err != nil && depth < maxDepth; depth++ {
+		if pcs := recordedCallers(err); len(pcs) > 0 {
+			found = pcs
+		}
+		inner := unwrapErrors(err)
+		if len(inner) == 0 {
+			break
+		}
+		err = inner[0]
+	}
+	return found
+}
+
+// recordedCallers supports the Callers() []uintptr convention and the
+// StackTrace() method of github.com/pkg/errors, whose result is a slice of
+// uintptr-based frames. The latter is read through reflection so the SDK does
+// not depend on it. Both are user methods, so a panic in them is ignored.
+func recordedCallers(err error) (pcs []uintptr) {
+	defer func() {
+		if recover() != nil {
+			pcs = nil
+		}
+	}()
+
+	if tracer, ok := err.(callersTracer); ok {
+		return tracer.Callers()
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+	out := method.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	trace := method.Call(nil)[0]
+	pcs = make([]uintptr, trace.Len())
+	for i := range pcs {
+		// pkg/errors stores the raw runtime.Callers values, so they can be
+		// passed to CallersFrames as is.
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs
+}
+
+// panicCallers drops every frame up to and including runtime.gopanic so the
+// trace starts at the function that panicked.
+func panicCallers(pcs []uintptr) []uintptr {
+	for i, pc := range pcs {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			return pcs[i+1:]
+		}
+	}
+	return pcs
+}
+
+// extractFrames converts program counters into frames, most recent call
+// first, leaving out the frames of this module.
+func (eb *EventBuilder) extractFrames(pcs []uintptr) []types.StackFrame {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	var result []types.StackFrame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isSDKFrame(frame) {
+			stackFrame := eb.newStackFrame(frame)
+			if eb.sources != nil && stackFrame.InApp {
+				eb.sources.apply(&stackFrame)
+			}
+			result = append(result, stackFrame)
+		}
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+func (eb *EventBuilder) newStackFrame(frame runtime.Frame) types.StackFrame {
+	pkg, function := panicparse.SplitFunctionName(frame.Function)
+	return types.StackFrame{
+		Function: function,
+		Package:  pkg,
+		File:     relativePath(frame.File, pkg),
+		AbsPath:  frame.File,
+		Line:     frame.Line,
+		InApp:    eb.isInApp(pkg, frame.File),
+	}
+}
+
+// isInApp matches the configured in-app prefixes. Without any, the main
+// module and package main are application code. Without build info,
+// everything outside the standard library, the module cache and vendor
+// directories is.
+func (eb *EventBuilder) isInApp(pkg, file string) bool {
+	if len(eb.inAppPrefixes) > 0 {
+		return hasPackagePrefix(pkg, eb.inAppPrefixes)
+	}
+	if eb.buildContext != nil && eb.buildContext.Module != "" {
+		return pkg == "main" || hasPackagePrefix(pkg, []string{eb.buildContext.Module})
+	}
+
+	if isStandardPackage(pkg) && pkg != "main" {
+		return false
+	}
+	return !strings.Contains(file, "/pkg/mod/") && !strings.Contains(file, "/vendor/")
+}
+
+func hasPackagePrefix(pkg string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if pkg == prefix || strings.HasPrefix(pkg, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isStandardPackage reports packages whose first path element has no dot,
+// which is how the go command tells standard library packages apart.
+func isStandardPackage(pkg string) bool {
+	first, _, _ := strings.Cut(pkg, "/")
+	return !strings.Contains(first, ".")
+}
+
+var workingDir, _ = os.Getwd()
+
+// relativePath shortens file to a path that is stable across machines:
+// relative to the module cache or the working directory, the import path for
+// the standard library, and otherwise anchored at the last directory of the
+// package path.
+func relativePath(file, pkg string) string {
+	if _, after, ok := strings.Cut(file, "/pkg/mod/"); ok {
+		return after
+	}
+	if rel, ok := trimDir(file, workingDir); ok {
+		return rel
+	}
+	if pkg != "" && pkg != "main" && isStandardPackage(pkg) {
+		return pkg + "/" + filepath.Base(file)
+	}
+	if pkg != "" {
+		dir := pkg[strings.LastIndex(pkg, "/")+1:]
+		if i := strings.LastIndex(file, "/"+dir+"/"); i >= 0 {
+			return file[i+1:]
+		}
+	}
+	return filepath.Base(file)
+}
+
+func trimDir(file, dir string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	prefix := filepath.ToSlash(dir) + "/"
+	if strings.HasPrefix(file, prefix) {
+		return strings.TrimPrefix(file, prefix), true
+	}
+	return "", false
+}
@@ -0,0 +1,97 @@
+package errortracker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+// crashFileHeader starts the crash file, so the next run knows which release
+// wrote it.
+const crashFileHeader = "royaltics-tracker release: "
+
+// openCrashFile empties the crash file and records the release, ready for
+// Start to register it.
+func (c *ErrorTrackerClient) openCrashFile() error {
+	if !crashOutputSupported {
+		return errors.New("crash file: requires Go 1.23 or later")
+	}
+
+	f, err := os.OpenFile(c.config.CrashFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("crash file: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%s%s\n", crashFileHeader, c.config.Version); err != nil {
+		f.Close()
+		return fmt.Errorf("crash file: %w", err)
+	}
+	c.crashFile = f
+	return nil
+}
+
+// registerCrashFile has the runtime write the output of a fatal crash after
+// the header. The runtime keeps its own descriptor, so the file is closed.
+func (c *ErrorTrackerClient) registerCrashFile() {
+	if c.crashFile == nil {
+		return
+	}
+	setCrashOutput(c.crashFile)
+	c.crashFile.Close()
+	c.crashFile = nil
+}
+
+// reportPreviousCrash sends the crash left in the crash file by the previous
+// run, tagged previous_run:true, and moves the file to CrashFile+".1".
+func (c *ErrorTrackerClient) reportPreviousCrash() error {
+	path := c.config.CrashFile
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("crash file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("crash file: %w", err)
+	}
+
+	release, hasRelease := "", false
+	if rest, ok := bytes.CutPrefix(data, []byte(crashFileHeader)); ok {
+		line, output, _ := bytes.Cut(rest, []byte("\n"))
+		release, hasRelease, data = string(line), true, output
+	}
+	if len(data) > maxCrashOutput {
+		data = data[len(data)-maxCrashOutput:]
+	}
+
+	ctx := context.Background()
+	scope := c.hub.Scope()
+	event, ok := c.eventBuilder.BuildCrash(ctx, data, scope)
+	if !ok {
+		return nil
+	}
+
+	if c.isEnabled {
+		event.Context.Tags = append(event.Context.Tags, "previous_run:true")
+		if hasRelease && release != c.config.Version {
+			// The build and modules describe this binary, not the one that crashed.
+			event.Context.Version = release
+			event.Context.Build = nil
+			event.Context.Modules = nil
+		}
+		event.Timestamp = info.ModTime().UTC().Format(time.RFC3339)
+		c.send(ctx, scope, event, types.Hint{})
+	}
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("crash file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package errortracker
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestCrashFile(t *testing.T) {
+	if !crashOutputSupported {
+		t.Skip("crash files require Go 1.23")
+	}
+
+	t.Run("should report the crash of the previous run", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "crash.log")
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCrashHelper$")
+		cmd.Env = append(os.Environ(), crashHelperEnv+"=crashfile", crashFileEnv+"="+path)
+		if err := cmd.Run(); err == nil {
+			t.Fatal("expected the helper to crash")
+		}
+
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.Version = "v1.1.0"
+			config.CrashFile = path
+		})
+
+		event := lastQueuedEvent(t, client)
+		if event.Title != "panic: runtime error: index out of range [2] with length 1" {
+			t.Errorf("unexpected title %s", event.Title)
+		}
+		if event.Level != string(types.LevelFatal) {
+			t.Errorf("expected FATAL, got %s", event.Level)
+		}
+		if !slices.Contains(event.Context.Tags, "previous_run:true") {
+			t.Errorf("expected previous_run tag, got %v", event.Context.Tags)
+		}
+		if event.Context.Version != "v1.0.0" || event.Context.Build != nil {
+			t.Errorf("expected the release of the crashed run, got %s %+v", event.Context.Version, event.Context.Build)
+		}
+		if data, _ := os.ReadFile(path); string(data) != crashFileHeader+"v1.1.0\n" {
+			t.Errorf("expected a new crash file, got %q", data)
+		}
+		if data, _ := os.ReadFile(path + ".1"); !strings.Contains(string(data), "index out of range") {
+			t.Errorf("expected the rotated crash file, got %q", data)
+		}
+	})
+
+	t.Run("should keep the version of the same release", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "crash.log")
+		output := crashFileHeader + "v1.1.0\nfatal error: concurrent map writes\n\ngoroutine 7 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d\n"
+		if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.Version = "v1.1.0"
+			config.CrashFile = path
+		})
+
+		event := lastQueuedEvent(t, client)
+		if event.Title != "fatal error: concurrent map writes" {
+			t.Errorf("unexpected title %s", event.Title)
+		}
+		if event.Context.Version != "v1.1.0" {
+			t.Errorf("expected v1.1.0, got %s", event.Context.Version)
+		}
+	})
+
+	t.Run("should ignore a crash file without a crash", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "crash.log")
+		if err := os.WriteFile(path, []byte(crashFileHeader+"v1.0.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.Version = "v1.1.0"
+			config.CrashFile = path
+		})
+
+		if queueLength(client) != 0 {
+			t.Errorf("expected no events, got %d", queueLength(client))
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected the crash file to stay, got %v", err)
+		}
+	})
+
+	t.Run("should start a new crash file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "crash.log")
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.Version = "v1.1.0"
+			config.CrashFile = path
+		})
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != crashFileHeader+"v1.1.0\n" {
+			t.Errorf("unexpected crash file %q", data)
+		}
+
+		client.registerCrashFile()
+		defer setCrashOutput(nil)
+		if client.crashFile != nil {
+			t.Error("expected the crash file to be handed to the runtime")
+		}
+	})
+
+	t.Run("should return crash file errors", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			CrashFile:     filepath.Join(t.TempDir(), "missing", "crash.log"),
+		}
+
+		if _, err := NewClient(config); err == nil || !strings.Contains(err.Error(), "crash file") {
+			t.Errorf("expected a crash file error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package errortracker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+// crashMonitorEnv marks the child process started by MonitorCrashes.
+const crashMonitorEnv = "ROYALTICS_TRACKER_MONITORED"
+
+// maxCrashOutput is how much of the end of stderr is kept for parsing.
+const maxCrashOutput = 1 << 20
+
+var forwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}
+
+// MonitorCrashes runs the program again as a child process and reports the
+// panic or fatal error that kills it, which an in-process recover cannot
+// see. Call it at the start of main, after Create. In the parent it does not
+// return: it forwards signals, waits for the child and exits with its
+// status. In the child it returns nil right away. Supported on Linux.
+func MonitorCrashes() error {
+	if os.Getenv(crashMonitorEnv) != "" {
+		return nil
+	}
+
+	client, err := Get()
+	if err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("monitor crashes: %w", err)
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), crashMonitorEnv+"=1")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+
+	code, err := client.monitorCrashes(cmd, os.Stderr)
+	if err != nil {
+		return err
+	}
+	Shutdown()
+	os.Exit(code)
+	return nil
+}
+
+// monitorCrashes runs cmd in its own process group, copying its stderr to
+// stderr, reports how it died and returns the exit code to use. Signals only
+// reach the child through the parent.
+func (c *ErrorTrackerClient) monitorCrashes(cmd *exec.Cmd, stderr io.Writer) (int, error) {
+	output := &tailBuffer{limit: maxCrashOutput}
+	cmd.Stderr = io.MultiWriter(stderr, output)
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = childProcAttr()
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("monitor crashes: %w", err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, forwardedSignals...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	signal.Stop(signals)
+	close(done)
+	if _, exited := err.(*exec.ExitError); err != nil && !exited {
+		return 0, fmt.Errorf("monitor crashes: %w", err)
+	}
+
+	code := cmd.ProcessState.ExitCode()
+	status, _ := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if status.Signaled() {
+		code = 128 + int(status.Signal())
+	}
+	if code == 0 || !c.isEnabled {
+		return code, nil
+	}
+
+	ctx := context.Background()
+	scope := c.hub.Scope()
+	if event, ok := c.eventBuilder.BuildCrash(ctx, output.Bytes(), scope); ok {
+		c.send(ctx, scope, event, types.Hint{})
+	} else if status.Signaled() {
+		c.send(ctx, scope, c.eventBuilder.BuildSignal(ctx, status.Signal(), scope), types.Hint{})
+	}
+	return code, nil
+}
+
+// tailBuffer keeps the last limit bytes written to it. It grows to twice the
+// limit before dropping older bytes, so each byte is moved at most once.
+type tailBuffer struct {
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	if len(p) >= b.limit {
+		b.data = append(b.data[:0], p[len(p)-b.limit:]...)
+		return len(p), nil
+	}
+	if len(b.data)+len(p) > 2*b.limit {
+		b.data = append(b.data[:0], b.data[len(b.data)-(b.limit-len(p)):]...)
+	}
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
+
+func (b *tailBuffer) Bytes() []byte {
+	if len(b.data) > b.limit {
+		return b.data[len(b.data)-b.limit:]
+	}
+	return b.data
+}
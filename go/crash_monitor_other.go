@@ -0,0 +1,9 @@
+//go:build !unix
+
+package errortracker
+
+import "syscall"
+
+func childProcAttr() *syscall.SysProcAttr {
+	return nil
+}
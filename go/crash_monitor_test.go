@@ -0,0 +1,187 @@
+package errortracker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+const (
+	crashHelperEnv = "TRACKER_CRASH_HELPER"
+	crashFileEnv   = "TRACKER_CRASH_FILE"
+	webhookEnv     = "TRACKER_WEBHOOK_URL"
+)
+
+// TestCrashHelper is the child process of the crash monitor tests.
+func TestCrashHelper(t *testing.T) {
+	switch os.Getenv(crashHelperEnv) {
+	case "":
+		t.Skip("helper process for TestMonitorCrashes")
+	case "panic":
+		done := make(chan struct{})
+		go func() {
+			chargeCard(3)
+			close(done)
+		}()
+		<-done
+	case "exit":
+		fmt.Fprintln(os.Stderr, "shutting down")
+		os.Exit(3)
+	case "kill":
+		signalSelf(t, os.Kill)
+		time.Sleep(time.Second)
+	case "ok":
+		os.Exit(0)
+	case "crashfile":
+		client, err := NewClient(&types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Version:       "v1.0.0",
+			Enabled:       true,
+			CrashFile:     os.Getenv(crashFileEnv),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Start()
+		go chargeCard(3)
+		time.Sleep(time.Second)
+	case "signals":
+		_, err := Create(&types.ClientConfig{
+			WebhookURL:    os.Getenv(webhookEnv),
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		HandleSignals(context.Background(), SignalOptions{RecordShutdown: true})
+		signalSelf(t, syscall.SIGTERM)
+		time.Sleep(5 * time.Second)
+	case "monitor":
+		client, err := NewClient(&types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCrashHelper$")
+		cmd.Env = append(os.Environ(), crashHelperEnv+"=interrupted")
+		cmd.Stdout = os.Stdout
+		code, err := client.monitorCrashes(cmd, os.Stderr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Exit(code)
+	case "interrupted":
+		interrupts := make(chan os.Signal, 2)
+		signal.Notify(interrupts, os.Interrupt)
+		fmt.Println("ready", os.Getpid())
+		<-interrupts
+		select {
+		case <-interrupts:
+			os.Exit(1)
+		case <-time.After(200 * time.Millisecond):
+			os.Exit(0)
+		}
+	}
+}
+
+func signalSelf(t *testing.T, sig os.Signal) {
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Signal(sig)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func chargeCard(amounts ...int) int {
+	return amounts[len(amounts)+1]
+}
+
+func TestCaptureCrash(t *testing.T) {
+	t.Run("should report crash output", func(t *testing.T) {
+		client := newContextTestClient(t)
+		output := "panic: boom [recovered]\n\tpanic: shutdown failed\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:9 +0x1d\n"
+
+		if !client.CaptureCrash(context.Background(), []byte(output), Fingerprint("crash")) {
+			t.Fatal("expected a crash")
+		}
+
+		event := lastQueuedEvent(t, client)
+		if event.Title != "panic: shutdown failed" || len(event.Event.Exceptions) != 2 {
+			t.Errorf("unexpected event %s %+v", event.Title, event.Event.Exceptions)
+		}
+		if event.Context.Culprit != "main.main:9" || event.Event.Frames[0].File != "main.go" {
+			t.Errorf("unexpected culprit %s and frames %+v", event.Context.Culprit, event.Event.Frames)
+		}
+		if len(event.Fingerprint) != 1 || event.Fingerprint[0] != "crash" {
+			t.Errorf("expected the fingerprint option, got %v", event.Fingerprint)
+		}
+		if event.Context.Host != nil || event.Context.OS != nil || event.Context.Build != nil || event.Context.Modules != nil {
+			t.Errorf("expected no context of this process, got %+v", event.Context)
+		}
+		if event.Context.Device != "test-device" {
+			t.Errorf("expected the configured device, got %s", event.Context.Device)
+		}
+		if hasTagKey(event.Context.Tags, "error") {
+			t.Errorf("expected no error type tag, got %v", event.Context.Tags)
+		}
+	})
+
+	t.Run("should ignore output without a crash", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		if client.CaptureCrash(context.Background(), []byte("exit status 1\n")) {
+			t.Error("expected no crash")
+		}
+		if queueLength(client) != 0 {
+			t.Errorf("expected no events, got %d", queueLength(client))
+		}
+	})
+}
+
+func TestTailBuffer(t *testing.T) {
+	t.Run("should keep the last bytes written", func(t *testing.T) {
+		buffer := &tailBuffer{limit: 8}
+		var written strings.Builder
+		for i := 0; i < 50; i++ {
+			line := fmt.Sprintf("%d\n", i)
+			buffer.Write([]byte(line))
+			written.WriteString(line)
+
+			want := written.String()
+			want = want[max(len(want)-8, 0):]
+			if got := string(buffer.Bytes()); got != want {
+				t.Fatalf("after %d writes expected %q, got %q", i+1, want, got)
+			}
+		}
+		if cap(buffer.data) > 2*8+8 {
+			t.Errorf("expected the buffer to stay near twice the limit, got %d", cap(buffer.data))
+		}
+	})
+
+	t.Run("should keep the end of large writes", func(t *testing.T) {
+		buffer := &tailBuffer{limit: 4}
+		buffer.Write([]byte("ab"))
+		buffer.Write([]byte("0123456789"))
+
+		if got := string(buffer.Bytes()); got != "6789" {
+			t.Errorf("expected 6789, got %q", got)
+		}
+	})
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package errortracker
+
+import "syscall"
+
+// childProcAttr starts the child in its own process group, so a Ctrl-C sent
+// to the foreground group of a terminal reaches it once, through the parent.
+func childProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
@@ -0,0 +1,133 @@
+//go:build unix
+
+package errortracker
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func runCrashHelper(t *testing.T, client *ErrorTrackerClient, mode string) (int, string) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCrashHelper$")
+	cmd.Env = append(os.Environ(), crashHelperEnv+"="+mode)
+
+	var stderr bytes.Buffer
+	code, err := client.monitorCrashes(cmd, &stderr)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return code, stderr.String()
+}
+
+func TestMonitorCrashes(t *testing.T) {
+	t.Run("should report panics of the child", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		code, stderr := runCrashHelper(t, client, "panic")
+
+		if code != 2 {
+			t.Errorf("expected exit code 2, got %d", code)
+		}
+		if !strings.Contains(stderr, "panic: runtime error: index out of range") {
+			t.Errorf("expected stderr to be passed through, got %s", stderr)
+		}
+
+		event := lastQueuedEvent(t, client)
+		if event.Level != string(types.LevelFatal) || event.Event.Name != "runtime.Error" {
+			t.Errorf("expected fatal runtime error, got %s %s", event.Level, event.Event.Name)
+		}
+		if event.Title != "panic: runtime error: index out of range [2] with length 1" {
+			t.Errorf("unexpected title %s", event.Title)
+		}
+		if event.Event.Mechanism == nil || event.Event.Mechanism.Type != "crash" {
+			t.Errorf("expected crash mechanism, got %+v", event.Event.Mechanism)
+		}
+		if !strings.Contains(event.Context.Culprit, "tracker-go.chargeCard") {
+			t.Errorf("expected culprit in chargeCard, got %s", event.Context.Culprit)
+		}
+		if len(event.Event.Frames) == 0 || event.Event.Frames[0].Function != "chargeCard" || !event.Event.Frames[0].InApp {
+			t.Errorf("expected crashing frame first, got %+v", event.Event.Frames)
+		}
+		if event.Context.Runtime != nil {
+			t.Error("expected no runtime context of the monitoring process")
+		}
+	})
+
+	t.Run("should not report clean exits or plain errors", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		if code, _ := runCrashHelper(t, client, "ok"); code != 0 {
+			t.Errorf("expected exit code 0, got %d", code)
+		}
+		code, stderr := runCrashHelper(t, client, "exit")
+		if code != 3 || !strings.Contains(stderr, "shutting down") {
+			t.Errorf("expected exit code 3 and stderr, got %d %q", code, stderr)
+		}
+		if queueLength(client) != 0 {
+			t.Errorf("expected no events, got %d", queueLength(client))
+		}
+	})
+
+	t.Run("should report children killed by a signal", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		code, _ := runCrashHelper(t, client, "kill")
+
+		if code != 128+int(syscall.SIGKILL) {
+			t.Errorf("expected exit code %d, got %d", 128+int(syscall.SIGKILL), code)
+		}
+		event := lastQueuedEvent(t, client)
+		if event.Title != "process killed by signal: killed" || event.Event.Name != "signal" {
+			t.Errorf("unexpected event %s %s", event.Title, event.Event.Name)
+		}
+		if hasTagKey(event.Context.Tags, "error") {
+			t.Errorf("expected no error type tag, got %v", event.Context.Tags)
+		}
+	})
+
+	t.Run("should deliver a terminal interrupt to the child once", func(t *testing.T) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCrashHelper$")
+		cmd.Env = append(os.Environ(), crashHelperEnv+"=monitor")
+		// Its own process group stands in for the foreground group of a terminal.
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		var child int
+		if _, err := fmt.Fscanf(bufio.NewReader(stdout), "ready %d\n", &child); err != nil {
+			t.Fatalf("expected the child to be ready, got %v", err)
+		}
+		if group, _ := syscall.Getpgid(child); group == cmd.Process.Pid {
+			t.Error("expected the child in its own process group")
+		}
+
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+		cmd.Wait()
+
+		if code := cmd.ProcessState.ExitCode(); code != 0 {
+			t.Errorf("expected the child to shut down cleanly, got exit code %d", code)
+		}
+	})
+
+	t.Run("should return in the child", func(t *testing.T) {
+		t.Setenv(crashMonitorEnv, "1")
+
+		if err := MonitorCrashes(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
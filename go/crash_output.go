@@ -0,0 +1,14 @@
+//go:build go1.23
+
+package errortracker
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+const crashOutputSupported = true
+
+func setCrashOutput(f *os.File) error {
+	return debug.SetCrashOutput(f, debug.CrashOptions{})
+}
@@ -0,0 +1,14 @@
+//go:build !go1.23
+
+package errortracker
+
+import (
+	"errors"
+	"os"
+)
+
+const crashOutputSupported = false
+
+func setCrashOutput(f *os.File) error {
+	return errors.New("crash output requires Go 1.23")
+}
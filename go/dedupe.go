@@ -0,0 +1,104 @@
+package errortracker
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+// deduper emits the first event of a fingerprint right away and counts the
+// repeats captured during the window that follows. When the window closes,
+// the last repeat is emitted once with the number of repeats.
+type deduper struct {
+	window  time.Duration
+	mu      sync.Mutex
+	pending map[string]*pendingEvent
+}
+
+type pendingEvent struct {
+	event     types.EventIssue
+	repeats   int
+	firstSeen time.Time
+	lastSeen  time.Time
+	timer     *time.Timer
+	emit      func(event types.EventIssue)
+}
+
+func newDeduper(window time.Duration) *deduper {
+	return &deduper{
+		window:  window,
+		pending: make(map[string]*pendingEvent),
+	}
+}
+
+// add reports whether event repeats one seen in the window. The first event
+// of a window is passed to emit before add returns.
+func (d *deduper) add(event types.EventIssue, emit func(event types.EventIssue)) bool {
+	key := strings.Join(event.Fingerprint, "\x00")
+	now := time.Now().UTC()
+
+	d.mu.Lock()
+	if entry, ok := d.pending[key]; ok {
+		if entry.repeats == 0 {
+			entry.firstSeen = now
+		}
+		entry.event = event
+		entry.emit = emit
+		entry.repeats++
+		entry.lastSeen = now
+		d.mu.Unlock()
+		return true
+	}
+
+	d.pending[key] = &pendingEvent{
+		timer: time.AfterFunc(d.window, func() { d.release(key) }),
+	}
+	d.mu.Unlock()
+
+	emit(event)
+	return false
+}
+
+func (d *deduper) release(key string) {
+	d.mu.Lock()
+	entry, ok := d.pending[key]
+	delete(d.pending, key)
+	d.mu.Unlock()
+
+	if ok {
+		entry.send()
+	}
+}
+
+// flush emits the repeats counted so far without waiting for the windows to
+// close.
+func (d *deduper) flush() {
+	d.mu.Lock()
+	entries := make([]*pendingEvent, 0, len(d.pending))
+	for key, entry := range d.pending {
+		entry.timer.Stop()
+		entries = append(entries, entry)
+		delete(d.pending, key)
+	}
+	d.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].firstSeen.Before(entries[j].firstSeen)
+	})
+	for _, entry := range entries {
+		entry.send()
+	}
+}
+
+func (e *pendingEvent) send() {
+	if e.repeats == 0 {
+		return
+	}
+	e.event.Occurrences = e.repeats
+	e.event.FirstSeen = e.firstSeen.Format(time.RFC3339Nano)
+	e.event.LastSeen = e.lastSeen.Format(time.RFC3339Nano)
+	e.emit(e.event)
+}
@@ -0,0 +1,108 @@
+package errortracker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestDedupe(t *testing.T) {
+	t.Run("should send the first event and then the number of repeats", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.DedupeWindow = 50 * time.Millisecond
+		})
+
+		for i := 0; i < 5; i++ {
+			client.Error(errors.New("dependency down"), types.LevelError, nil)
+		}
+
+		if queueLength(client) != 1 {
+			t.Fatalf("expected the first event to be sent right away, got %d queued", queueLength(client))
+		}
+		if event := lastQueuedEvent(t, client); event.Occurrences != 0 {
+			t.Errorf("expected the first event without occurrences, got %d", event.Occurrences)
+		}
+
+		deadline := time.Now().Add(2 * time.Second)
+		for queueLength(client) == 1 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if queueLength(client) != 2 {
+			t.Fatalf("expected 2 events after the window, got %d", queueLength(client))
+		}
+
+		event := lastQueuedEvent(t, client)
+		if event.Occurrences != 4 {
+			t.Errorf("expected 4 occurrences, got %d", event.Occurrences)
+		}
+		if event.FirstSeen == "" || event.LastSeen == "" || event.LastSeen < event.FirstSeen {
+			t.Errorf("expected first and last seen, got %q and %q", event.FirstSeen, event.LastSeen)
+		}
+		if stats := client.Stats(); stats.EventsSuppressed != 4 {
+			t.Errorf("expected 4 suppressed events, got %d", stats.EventsSuppressed)
+		}
+	})
+
+	t.Run("should keep different fingerprints apart", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.DedupeWindow = time.Hour
+		})
+
+		client.Error(errors.New("a"), types.LevelError, nil, Fingerprint("a"))
+		client.Error(errors.New("b"), types.LevelError, nil, Fingerprint("b"))
+		client.Error(errors.New("a"), types.LevelError, nil, Fingerprint("a"))
+
+		client.dedupe.flush()
+
+		client.queueMu.Lock()
+		events := append([]types.EventIssue(nil), client.eventQueue...)
+		client.queueMu.Unlock()
+
+		if len(events) != 3 {
+			t.Fatalf("expected 3 events, got %d", len(events))
+		}
+		if events[0].Fingerprint[0] != "a" || events[1].Fingerprint[0] != "b" {
+			t.Errorf("expected a and b to be sent right away, got %v and %v", events[0].Fingerprint, events[1].Fingerprint)
+		}
+		if events[2].Fingerprint[0] != "a" || events[2].Occurrences != 1 {
+			t.Errorf("expected a with 1 repeat, got %v with %d", events[2].Fingerprint, events[2].Occurrences)
+		}
+	})
+
+	t.Run("should not send a count without repeats", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.DedupeWindow = time.Hour
+		})
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+		client.dedupe.flush()
+
+		if queueLength(client) != 1 {
+			t.Errorf("expected 1 event, got %d", queueLength(client))
+		}
+	})
+
+	t.Run("should run processors on the first event and the count", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.DedupeWindow = time.Hour
+		})
+
+		calls := 0
+		client.AddEventProcessor(func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+			calls++
+			return event
+		})
+
+		for i := 0; i < 3; i++ {
+			client.Error(errors.New("boom"), types.LevelError, nil)
+		}
+		client.dedupe.flush()
+
+		if calls != 2 {
+			t.Errorf("expected processors to run twice, got %d", calls)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package errortracker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestErrorChain(t *testing.T) {
+	t.Run("should use the root cause as primary type", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		err := fmt.Errorf("checkout: %w", fmt.Errorf("charge card: %w", &paymentError{code: 402}))
+		client.Error(err, types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if event.Event.Name != "*errortracker.paymentError" {
+			t.Errorf("expected root cause type, got %s", event.Event.Name)
+		}
+		if event.Event.Message != "checkout: charge card: payment failed" {
+			t.Errorf("expected full message, got %s", event.Event.Message)
+		}
+		if !hasTag(event.Context.Tags, "error:*errortracker.paymentError") {
+			t.Errorf("expected root cause tag, got %v", event.Context.Tags)
+		}
+
+		exceptions := event.Event.Exceptions
+		if len(exceptions) != 3 {
+			t.Fatalf("expected 3 exceptions, got %d", len(exceptions))
+		}
+		if exceptions[0].Name != "*fmt.wrapError" || exceptions[0].Parent != -1 {
+			t.Errorf("expected outermost wrapper first, got %+v", exceptions[0])
+		}
+		if exceptions[2].Name != "*errortracker.paymentError" || exceptions[2].Message != "payment failed" || exceptions[2].Parent != 1 {
+			t.Errorf("expected root cause last, got %+v", exceptions[2])
+		}
+	})
+
+	t.Run("should walk joined errors", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		err := fmt.Errorf("sync: %w", errors.Join(io.ErrUnexpectedEOF, &paymentError{code: 500}))
+		client.Error(err, types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if event.Event.Name != "*errors.errorString" {
+			t.Errorf("expected first joined error as root cause, got %s", event.Event.Name)
+		}
+
+		exceptions := event.Event.Exceptions
+		if len(exceptions) != 4 {
+			t.Fatalf("expected 4 exceptions, got %d", len(exceptions))
+		}
+		if exceptions[1].Name != "*errors.joinError" || exceptions[1].Parent != 0 {
+			t.Errorf("expected join error below the wrapper, got %+v", exceptions[1])
+		}
+		if exceptions[2].Parent != 1 || exceptions[3].Parent != 1 {
+			t.Errorf("expected joined errors to share a parent, got %+v", exceptions[2:])
+		}
+		if exceptions[3].Name != "*errortracker.paymentError" {
+			t.Errorf("expected second joined error, got %s", exceptions[3].Name)
+		}
+	})
+
+	t.Run("should not list exceptions for unwrapped errors", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(errors.New("plain"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if event.Event.Name != "*errors.errorString" {
+			t.Errorf("expected *errors.errorString, got %s", event.Event.Name)
+		}
+		if len(event.Event.Exceptions) != 0 {
+			t.Errorf("expected no exceptions, got %+v", event.Event.Exceptions)
+		}
+	})
+
+	t.Run("should honour the depth limit", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+			MaxErrorDepth: 2,
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		wrapped := fmt.Errorf("a: %w", fmt.Errorf("b: %w", fmt.Errorf("c: %w", &paymentError{})))
+		client.Error(wrapped, types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Event.Exceptions) != 2 {
+			t.Errorf("expected 2 exceptions, got %d", len(event.Event.Exceptions))
+		}
+		if event.Event.Name != "*fmt.wrapError" {
+			t.Errorf("expected deepest reachable type, got %s", event.Event.Name)
+		}
+	})
+
+	t.Run("should reject an out of range depth limit", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			MaxErrorDepth: 101,
+		}
+
+		if _, err := NewClient(config); err == nil {
+			t.Error("expected error for maxErrorDepth above 100")
+		}
+	})
+}
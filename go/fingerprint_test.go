@@ -0,0 +1,93 @@
+package errortracker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+func failOrder(client *ErrorTrackerClient, id int, options ...CaptureOption) types.EventIssue {
+	client.Error(fmt.Errorf("order %d failed: %w", id, &paymentError{code: 402}), types.LevelError, nil, options...)
+
+	client.queueMu.Lock()
+	defer client.queueMu.Unlock()
+	return client.eventQueue[len(client.eventQueue)-1]
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Run("should group events that only differ in variable data", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		first := failOrder(client, 123)
+		second := failOrder(client, 456)
+
+		if len(first.Fingerprint) != 1 || first.Fingerprint[0] == "" {
+			t.Fatalf("expected a default fingerprint, got %v", first.Fingerprint)
+		}
+		if first.Fingerprint[0] != second.Fingerprint[0] {
+			t.Errorf("expected equal fingerprints, got %v and %v", first.Fingerprint, second.Fingerprint)
+		}
+	})
+
+	t.Run("should separate different root causes", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		for _, err := range []error{errors.New("a"), &paymentError{}} {
+			client.Error(err, types.LevelError, nil)
+		}
+
+		client.queueMu.Lock()
+		first, second := client.eventQueue[0], client.eventQueue[1]
+		client.queueMu.Unlock()
+
+		if first.Fingerprint[0] == second.Fingerprint[0] {
+			t.Errorf("expected different fingerprints, got %v", first.Fingerprint)
+		}
+	})
+
+	t.Run("should use the scope fingerprint", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.SetFingerprint([]string{"payments"})
+		})
+
+		event := failOrder(client, 1)
+		if len(event.Fingerprint) != 1 || event.Fingerprint[0] != "payments" {
+			t.Errorf("expected scope fingerprint, got %v", event.Fingerprint)
+		}
+	})
+
+	t.Run("should prefer the call option and expand the default", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.SetFingerprint([]string{"payments"})
+		})
+
+		plain := failOrder(client, 1, Fingerprint(core.DefaultFingerprint))
+		event := failOrder(client, 2, Fingerprint(core.DefaultFingerprint, "tenant-a"))
+
+		if len(event.Fingerprint) != 2 || event.Fingerprint[1] != "tenant-a" {
+			t.Fatalf("expected expanded fingerprint, got %v", event.Fingerprint)
+		}
+		if event.Fingerprint[0] == core.DefaultFingerprint || event.Fingerprint[0] != plain.Fingerprint[0] {
+			t.Errorf("expected default fingerprint in first position, got %v", event.Fingerprint)
+		}
+	})
+
+	t.Run("should fingerprint panics", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		func() {
+			defer client.Recover()
+			panicWithString()
+		}()
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Fingerprint) != 1 || event.Fingerprint[0] == "" {
+			t.Errorf("expected a default fingerprint, got %v", event.Fingerprint)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package errortracker
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func blockedWorker(ready *sync.WaitGroup, release chan struct{}) {
+	ready.Done()
+	<-release
+}
+
+func startBlockedWorkers(t *testing.T, n int) {
+	t.Helper()
+
+	var ready sync.WaitGroup
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	ready.Add(n)
+	for i := 0; i < n; i++ {
+		go blockedWorker(&ready, release)
+	}
+	ready.Wait()
+	// Let the workers park on the channel.
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestGoroutineDump(t *testing.T) {
+	t.Run("should group identical goroutines on fatal events", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.AttachGoroutines = true
+		})
+		startBlockedWorkers(t, 20)
+
+		func() {
+			defer client.Recover()
+			panicWithString()
+		}()
+
+		dump := lastQueuedEvent(t, client).Goroutines
+		if dump == nil || dump.Total < 21 || dump.Truncated {
+			t.Fatalf("expected a complete dump, got %+v", dump)
+		}
+
+		var workers *types.GoroutineGroup
+		for i, group := range dump.Groups {
+			if len(group.Frames) > 0 && strings.Contains(group.Frames[len(group.Frames)-1].Function, "blockedWorker") {
+				workers = &dump.Groups[i]
+			}
+		}
+		if workers == nil || workers.Count != 20 || len(workers.IDs) != 10 {
+			t.Fatalf("expected one group of 20 workers, got %+v", workers)
+		}
+		if workers.State != "chan receive" || workers.CreatedBy == nil {
+			t.Errorf("expected parked workers with a creator, got %+v", workers)
+		}
+		if dump.Groups[0].Count < workers.Count {
+			t.Errorf("expected largest group first, got %d", dump.Groups[0].Count)
+		}
+
+		running := false
+		for _, group := range dump.Groups {
+			running = running || group.State == "running"
+		}
+		if !running {
+			t.Error("expected the panicking goroutine to be running")
+		}
+	})
+
+	t.Run("should skip other levels", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.AttachGoroutines = true
+		})
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		if dump := lastQueuedEvent(t, client).Goroutines; dump != nil {
+			t.Errorf("expected no dump, got %+v", dump)
+		}
+	})
+
+	t.Run("should mark truncated dumps", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.AttachGoroutines = true
+			config.MaxGoroutineDumpBytes = 2048
+		})
+		startBlockedWorkers(t, 20)
+
+		client.Event("shutting down", types.LevelFatal, nil)
+
+		dump := lastQueuedEvent(t, client).Goroutines
+		if dump == nil || !dump.Truncated || dump.Total == 0 {
+			t.Errorf("expected a truncated dump, got %+v", dump)
+		}
+	})
+
+	t.Run("should be disabled by default", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Event("shutting down", types.LevelFatal, nil)
+
+		if dump := lastQueuedEvent(t, client).Goroutines; dump != nil {
+			t.Errorf("expected no dump, got %+v", dump)
+		}
+	})
+}
@@ -0,0 +1,19 @@
+module github.com/royaltics/tracker-go/grpc
+
+go 1.21
+
+require (
+	github.com/royaltics/tracker-go v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.66.2
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
+	google.golang.org/protobuf v1.34.1 // indirect
+)
+
+replace github.com/royaltics/tracker-go => ../
@@ -0,0 +1,289 @@
+package trackergrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var defaultReportCodes = []codes.Code{
+	codes.Unknown,
+	codes.Internal,
+	codes.Unimplemented,
+	codes.Unavailable,
+	codes.DataLoss,
+}
+
+type Options struct {
+	// Client defaults to the default instance, resolved on every call.
+	Client *errortracker.ErrorTrackerClient
+	// Repanic panics again after reporting instead of returning codes.Internal.
+	Repanic bool
+	// WaitForDelivery flushes the client before panicking again.
+	WaitForDelivery bool
+	// ReportCodes lists the status codes reported as events. Defaults to
+	// Unknown, Internal, Unimplemented, Unavailable and DataLoss.
+	ReportCodes []codes.Code
+	// MetadataKeys lists the metadata keys attached to events.
+	MetadataKeys []string
+}
+
+type interceptor struct {
+	options     Options
+	reportCodes map[codes.Code]bool
+}
+
+func newInterceptor(options Options) *interceptor {
+	if options.ReportCodes == nil {
+		options.ReportCodes = defaultReportCodes
+	}
+
+	reportCodes := make(map[codes.Code]bool, len(options.ReportCodes))
+	for _, code := range options.ReportCodes {
+		reportCodes[code] = true
+	}
+
+	return &interceptor{
+		options:     options,
+		reportCodes: reportCodes,
+	}
+}
+
+func UnaryServerInterceptor(options Options) grpc.UnaryServerInterceptor {
+	i := newInterceptor(options)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		client := i.client()
+		if client == nil {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		hub, ctx := i.startHub(ctx, client, info.FullMethod)
+		defer func() {
+			if value := recover(); value != nil {
+				err = i.handlePanic(ctx, client, hub, value, info.FullMethod, start)
+			}
+		}()
+
+		resp, err = handler(ctx, req)
+		i.report(ctx, hub, err, info.FullMethod, start, metadata.FromIncomingContext, peerMetadata(ctx))
+		return resp, err
+	}
+}
+
+func StreamServerInterceptor(options Options) grpc.StreamServerInterceptor {
+	i := newInterceptor(options)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		client := i.client()
+		if client == nil {
+			return handler(srv, ss)
+		}
+
+		start := time.Now()
+		hub, ctx := i.startHub(ss.Context(), client, info.FullMethod)
+		defer func() {
+			if value := recover(); value != nil {
+				err = i.handlePanic(ctx, client, hub, value, info.FullMethod, start)
+			}
+		}()
+
+		err = handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		i.report(ctx, hub, err, info.FullMethod, start, metadata.FromIncomingContext, peerMetadata(ctx))
+		return err
+	}
+}
+
+func UnaryClientInterceptor(options Options) grpc.UnaryClientInterceptor {
+	i := newInterceptor(options)
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if hub := i.hubFor(ctx); hub != nil {
+			i.report(ctx, hub, err, method, start, metadata.FromOutgoingContext, targetMetadata(cc, opts))
+		}
+		return err
+	}
+}
+
+func StreamClientInterceptor(options Options) grpc.StreamClientInterceptor {
+	i := newInterceptor(options)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		hub := i.hubFor(ctx)
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if hub == nil {
+			return stream, err
+		}
+		if err != nil {
+			i.report(ctx, hub, err, method, start, metadata.FromOutgoingContext, targetMetadata(cc, opts))
+			return stream, err
+		}
+
+		return &clientStream{
+			ClientStream: stream,
+			report: func(err error) {
+				i.report(ctx, hub, err, method, start, metadata.FromOutgoingContext, targetMetadata(cc, opts))
+			},
+		}, nil
+	}
+}
+
+func (i *interceptor) client() *errortracker.ErrorTrackerClient {
+	if i.options.Client != nil {
+		return i.options.Client
+	}
+	client, err := errortracker.Get()
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+// hubFor returns the hub of an outgoing call: the caller's hub when the
+// context carries one, otherwise the client's root hub.
+func (i *interceptor) hubFor(ctx context.Context) *errortracker.Hub {
+	if hub := errortracker.HubFromContext(ctx); hub != nil {
+		return hub
+	}
+	if client := i.client(); client != nil {
+		return client.Hub()
+	}
+	return nil
+}
+
+func (i *interceptor) startHub(ctx context.Context, client *errortracker.ErrorTrackerClient, method string) (*errortracker.Hub, context.Context) {
+	hub := client.Hub().Clone()
+	hub.Scope().SetTag("grpc.method", method)
+	return hub, errortracker.ContextWithHub(ctx, hub)
+}
+
+func (i *interceptor) handlePanic(ctx context.Context, client *errortracker.ErrorTrackerClient, hub *errortracker.Hub, value any, method string, start time.Time) error {
+	meta := i.callMetadata(ctx, method, start, metadata.FromIncomingContext)
+	maps.Copy(meta, peerMetadata(ctx))
+	hub.Scope().SetExtras(meta)
+	client.CapturePanic(ctx, value)
+
+	if i.options.Repanic {
+		if i.options.WaitForDelivery {
+			client.ForceFlush()
+		}
+		panic(value)
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+func (i *interceptor) report(
+	ctx context.Context,
+	hub *errortracker.Hub,
+	err error,
+	method string,
+	start time.Time,
+	mdFromContext func(context.Context) (metadata.MD, bool),
+	extra map[string]string,
+) {
+	if err == nil {
+		return
+	}
+
+	code := status.Code(err)
+	if !i.reportCodes[code] {
+		return
+	}
+
+	meta := i.callMetadata(ctx, method, start, mdFromContext)
+	meta["grpc.code"] = code.String()
+	for key, value := range extra {
+		meta[key] = value
+	}
+
+	// The stack is the one of the interceptor, so group by method and code.
+	hub.ErrorCtx(ctx, err, types.LevelError, meta,
+		errortracker.Fingerprint(core.DefaultFingerprint, method, code.String()))
+}
+
+func (i *interceptor) callMetadata(ctx context.Context, method string, start time.Time, mdFromContext func(context.Context) (metadata.MD, bool)) map[string]string {
+	meta := map[string]string{
+		"grpc.method": method,
+		"latency_ms":  strconv.FormatInt(time.Since(start).Milliseconds(), 10),
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		meta["grpc.deadline"] = deadline.UTC().Format(time.RFC3339Nano)
+		meta["grpc.deadline_remaining_ms"] = strconv.FormatInt(time.Until(deadline).Milliseconds(), 10)
+	}
+
+	if md, ok := mdFromContext(ctx); ok {
+		for _, key := range i.options.MetadataKeys {
+			if values := md.Get(key); len(values) > 0 {
+				meta["grpc.metadata."+strings.ToLower(key)] = strings.Join(values, ",")
+			}
+		}
+	}
+
+	return meta
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	report func(err error)
+	once   sync.Once
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.once.Do(func() {
+			s.report(err)
+		})
+	}
+	return err
+}
+
+// peerMetadata returns the address of the caller of a server call.
+func peerMetadata(ctx context.Context) map[string]string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return map[string]string{"grpc.peer": p.Addr.String()}
+	}
+	return nil
+}
+
+// targetMetadata returns the target of a client call, and the server it
+// reached when the caller passed grpc.Peer.
+func targetMetadata(cc *grpc.ClientConn, opts []grpc.CallOption) map[string]string {
+	meta := map[string]string{"grpc.target": cc.Target()}
+	for _, opt := range opts {
+		if p, ok := opt.(grpc.PeerCallOption); ok && p.PeerAddr != nil && p.PeerAddr.Addr != nil {
+			meta["grpc.peer"] = p.PeerAddr.Addr.String()
+		}
+	}
+	return meta
+}
@@ -0,0 +1,247 @@
+package trackergrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/internal/trackertest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	check func(ctx context.Context) error
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if err := s.check(ctx); err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	if err := s.check(stream.Context()); err != nil {
+		return err
+	}
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+}
+
+func startServer(t *testing.T, options Options, check func(ctx context.Context) error, dialOptions ...grpc.DialOption) grpc_health_v1.HealthClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(options)),
+		grpc.StreamInterceptor(StreamServerInterceptor(options)),
+	)
+	grpc_health_v1.RegisterHealthServer(server, &healthServer{check: check})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	dialOptions = append(dialOptions,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.Dial("passthrough:///bufnet", dialOptions...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Run("should report panics as internal errors", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		health := startServer(t, Options{Client: client}, func(ctx context.Context) error {
+			panic("boom")
+		})
+
+		_, err := health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		if status.Code(err) != codes.Internal || status.Convert(err).Message() != "internal error" {
+			t.Errorf("expected a generic Internal error, got %v", err)
+		}
+
+		recorded := events()
+		if len(recorded) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recorded))
+		}
+		event := recorded[0]
+		if event.Event.Mechanism == nil || event.Event.Mechanism.Type != "panic" {
+			t.Errorf("expected panic mechanism, got %+v", event.Event.Mechanism)
+		}
+		if event.Context.Extra["grpc.method"] != "/grpc.health.v1.Health/Check" {
+			t.Errorf("expected full method, got %v", event.Context.Extra)
+		}
+		if event.Context.Extra["grpc.peer"] == "" {
+			t.Error("expected peer address")
+		}
+	})
+
+	t.Run("should report configured status codes", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		options := Options{
+			Client:       client,
+			MetadataKeys: []string{"x-request-id"},
+		}
+		health := startServer(t, options, func(ctx context.Context) error {
+			return status.Error(codes.Unavailable, "database down")
+		})
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-1", "authorization", "secret")
+		ctx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		health.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+
+		recorded := events()
+		if len(recorded) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recorded))
+		}
+		extra := recorded[0].Context.Extra
+		if extra["grpc.code"] != "Unavailable" {
+			t.Errorf("expected Unavailable, got %s", extra["grpc.code"])
+		}
+		if extra["grpc.metadata.x-request-id"] != "req-1" {
+			t.Errorf("expected selected metadata, got %v", extra)
+		}
+		if _, ok := extra["grpc.metadata.authorization"]; ok {
+			t.Error("expected unselected metadata to be dropped")
+		}
+		if extra["grpc.deadline"] == "" {
+			t.Error("expected deadline info")
+		}
+	})
+
+	t.Run("should ignore unlisted status codes", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		health := startServer(t, Options{Client: client}, func(ctx context.Context) error {
+			return status.Error(codes.NotFound, "no such service")
+		})
+
+		health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+
+		if recorded := events(); len(recorded) != 0 {
+			t.Errorf("expected no events, got %d", len(recorded))
+		}
+	})
+
+	t.Run("should put a request scoped hub in the context", func(t *testing.T) {
+		client, _ := trackertest.NewRecordingClient(t)
+
+		var hub *errortracker.Hub
+		health := startServer(t, Options{Client: client}, func(ctx context.Context) error {
+			hub = errortracker.HubFromContext(ctx)
+			return nil
+		})
+
+		health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+
+		if hub == nil || hub == client.Hub() {
+			t.Error("expected a cloned hub in the handler context")
+		}
+	})
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	t.Run("should report stream panics and errors", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+
+		calls := 0
+		health := startServer(t, Options{Client: client}, func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				panic("stream boom")
+			}
+			return status.Error(codes.Internal, "stream failed")
+		})
+
+		for i := 0; i < 2; i++ {
+			stream, err := health.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if _, err := stream.Recv(); status.Code(err) != codes.Internal {
+				t.Errorf("expected Internal, got %v", err)
+			}
+		}
+
+		recorded := events()
+		if len(recorded) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(recorded))
+		}
+		for _, event := range recorded {
+			if event.Context.Extra["grpc.method"] != "/grpc.health.v1.Health/Watch" {
+				t.Errorf("expected watch method, got %v", event.Context.Extra)
+			}
+		}
+	})
+}
+
+func TestClientInterceptors(t *testing.T) {
+	t.Run("should report failed unary and stream calls", func(t *testing.T) {
+		serverClient, _ := trackertest.NewRecordingClient(t)
+		client, events := trackertest.NewRecordingClient(t)
+
+		options := Options{Client: client}
+		health := startServer(t, Options{Client: serverClient}, func(ctx context.Context) error {
+			return status.Error(codes.Unavailable, "overloaded")
+		},
+			grpc.WithUnaryInterceptor(UnaryClientInterceptor(options)),
+			grpc.WithStreamInterceptor(StreamClientInterceptor(options)),
+		)
+
+		var p peer.Peer
+		health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Peer(&p))
+
+		stream, err := health.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		stream.Recv()
+		stream.Recv()
+
+		recorded := events()
+		if len(recorded) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(recorded))
+		}
+		for _, event := range recorded {
+			if event.Context.Extra["grpc.target"] != "passthrough:///bufnet" {
+				t.Errorf("expected target, got %v", event.Context.Extra)
+			}
+			if event.Context.Extra["grpc.code"] != "Unavailable" {
+				t.Errorf("expected Unavailable, got %v", event.Context.Extra)
+			}
+			if len(event.Fingerprint) != 3 || event.Fingerprint[1] != event.Context.Extra["grpc.method"] || event.Fingerprint[2] != "Unavailable" {
+				t.Errorf("expected the method and code in the fingerprint, got %v", event.Fingerprint)
+			}
+		}
+		for _, event := range recorded {
+			peerAddr, ok := event.Context.Extra["grpc.peer"]
+			switch event.Context.Extra["grpc.method"] {
+			case "/grpc.health.v1.Health/Check":
+				if peerAddr != p.Addr.String() {
+					t.Errorf("expected the peer of the call option, got %v", event.Context.Extra)
+				}
+			default:
+				if ok {
+					t.Errorf("expected no peer without the call option, got %v", event.Context.Extra)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package errortracker
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+const testContainerID = "3f4e5d6c7b8a99887766554433221100ffeeddccbbaa00112233445566778899"
+
+func fakeEnv(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func fakeHostname(hostname string) func() (string, error) {
+	return func() (string, error) {
+		if hostname == "" {
+			return "", errors.New("no hostname")
+		}
+		return hostname, nil
+	}
+}
+
+func TestHostContext(t *testing.T) {
+	t.Run("should attach host and os context", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		hostname, _ := os.Hostname()
+		if event.Context.Host == nil || event.Context.Host.Hostname != hostname {
+			t.Errorf("expected hostname %s, got %+v", hostname, event.Context.Host)
+		}
+		if event.Context.OS == nil || event.Context.OS.Name == "" {
+			t.Errorf("expected os context, got %+v", event.Context.OS)
+		}
+
+		event.Context.Host.Hostname = "changed"
+		client.Error(errors.New("boom"), types.LevelError, nil)
+		if lastQueuedEvent(t, client).Context.Host.Hostname != hostname {
+			t.Error("expected every event to get its own host context")
+		}
+	})
+
+	t.Run("should read os release, kernel and boot time", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"etc/os-release":            {Data: []byte("NAME=\"Ubuntu\"\nVERSION_ID=\"22.04\"\nPRETTY_NAME=\"Ubuntu 22.04.4 LTS\"\n# comment\n")},
+			"proc/sys/kernel/osrelease": {Data: []byte("5.15.0-105-generic\n")},
+			"proc/stat":                 {Data: []byte("cpu  1 2 3\nbtime 1700000000\nprocesses 42\n")},
+		}
+
+		host, osContext := core.DetectHost(fsys, fakeEnv(nil), fakeHostname("web-1"))
+
+		if osContext.Name != "Ubuntu" || osContext.Version != "22.04" || osContext.PrettyName != "Ubuntu 22.04.4 LTS" {
+			t.Errorf("unexpected os context %+v", osContext)
+		}
+		if osContext.KernelVersion != "5.15.0-105-generic" {
+			t.Errorf("expected kernel version, got %s", osContext.KernelVersion)
+		}
+		if host.BootTime != "2023-11-14T22:13:20Z" {
+			t.Errorf("expected boot time, got %s", host.BootTime)
+		}
+		if host.ContainerID != "" || host.Kubernetes != nil {
+			t.Errorf("expected no container context, got %+v", host)
+		}
+	})
+
+	t.Run("should fall back to GOOS without os release", func(t *testing.T) {
+		_, osContext := core.DetectHost(fstest.MapFS{}, fakeEnv(nil), fakeHostname("web-1"))
+		if osContext.Name != runtime.GOOS {
+			t.Errorf("expected %s, got %s", runtime.GOOS, osContext.Name)
+		}
+	})
+
+	t.Run("should fall back to HOSTNAME and /etc/hostname", func(t *testing.T) {
+		fsys := fstest.MapFS{"etc/hostname": {Data: []byte("web-2\n")}}
+
+		if host, _ := core.DetectHost(fsys, fakeEnv(nil), fakeHostname("web-1")); host.Hostname != "web-1" {
+			t.Errorf("expected the looked up hostname, got %s", host.Hostname)
+		}
+		env := fakeEnv(map[string]string{"HOSTNAME": "web-3"})
+		if host, _ := core.DetectHost(fsys, env, fakeHostname("")); host.Hostname != "web-3" {
+			t.Errorf("expected HOSTNAME, got %s", host.Hostname)
+		}
+		if host, _ := core.DetectHost(fsys, fakeEnv(nil), fakeHostname("")); host.Hostname != "web-2" {
+			t.Errorf("expected /etc/hostname, got %s", host.Hostname)
+		}
+	})
+
+	t.Run("should find the container id", func(t *testing.T) {
+		cgroups := map[string]string{
+			"docker":     "12:memory:/docker/" + testContainerID + "\n",
+			"containerd": "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + testContainerID + ".scope\n",
+		}
+		for name, cgroup := range cgroups {
+			host, _ := core.DetectHost(fstest.MapFS{"proc/self/cgroup": {Data: []byte(cgroup)}}, fakeEnv(nil), fakeHostname("web-1"))
+			if host.ContainerID != testContainerID {
+				t.Errorf("%s: expected container id, got %q", name, host.ContainerID)
+			}
+		}
+
+		fsys := fstest.MapFS{
+			"proc/self/cgroup":    {Data: []byte("0::/\n")},
+			"proc/self/mountinfo": {Data: []byte("631 612 254:1 /docker/containers/" + testContainerID + "/hostname /etc/hostname rw - ext4 /dev/vda1 rw\n")},
+		}
+		if host, _ := core.DetectHost(fsys, fakeEnv(nil), fakeHostname("web-1")); host.ContainerID != testContainerID {
+			t.Errorf("expected container id from mountinfo, got %q", host.ContainerID)
+		}
+	})
+
+	t.Run("should detect kubernetes", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"var/run/secrets/kubernetes.io/serviceaccount/namespace": {Data: []byte("payments")},
+		}
+		env := fakeEnv(map[string]string{
+			"KUBERNETES_SERVICE_HOST": "10.0.0.1",
+			"POD_NAME":                "checkout-7d9f",
+			"NODE_NAME":               "node-a",
+		})
+
+		host, _ := core.DetectHost(fsys, env, fakeHostname("web-1"))
+
+		kubernetes := host.Kubernetes
+		if kubernetes == nil || kubernetes.PodName != "checkout-7d9f" || kubernetes.NodeName != "node-a" {
+			t.Fatalf("unexpected kubernetes context %+v", kubernetes)
+		}
+		if kubernetes.Namespace != "payments" {
+			t.Errorf("expected service account namespace, got %s", kubernetes.Namespace)
+		}
+	})
+}
@@ -0,0 +1,373 @@
+package trackerhttp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+var defaultHeaderAllowlist = []string{
+	"Accept",
+	"Accept-Encoding",
+	"Accept-Language",
+	"Content-Length",
+	"Content-Type",
+	"Referer",
+	"User-Agent",
+	"X-Request-Id",
+}
+
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Proxy-Authorization": true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+	"X-Auth-Token":        true,
+	"X-Csrf-Token":        true,
+}
+
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+type Options struct {
+	// Client defaults to the default instance, resolved on every request.
+	Client *errortracker.ErrorTrackerClient
+	// Repanic panics again after reporting instead of answering 500.
+	Repanic bool
+	// WaitForDelivery flushes the client before panicking again.
+	WaitForDelivery bool
+	// CaptureStatus lists the response codes reported as events. Defaults to
+	// 500-599.
+	CaptureStatus []StatusRange
+	// HeaderAllowlist lists the request headers attached to events. Sensitive
+	// headers are always filtered.
+	HeaderAllowlist []string
+	// MaxRequestBodySize caps the request body bytes attached to events. Zero
+	// disables body capture.
+	MaxRequestBodySize int64
+	// RouteFunc returns the route template of a request. Defaults to the URL
+	// path.
+	RouteFunc func(r *http.Request) string
+	// UserFunc returns the user of a request, or nil when it is anonymous.
+	// It runs before the wrapped handler.
+	UserFunc func(r *http.Request) *types.User
+	// TrustedProxies lists the proxies whose X-Forwarded-For and X-Real-Ip
+	// headers are believed. By default the connection address is reported.
+	TrustedProxies []netip.Prefix
+}
+
+type Handler struct {
+	options Options
+}
+
+func New(options Options) *Handler {
+	if len(options.CaptureStatus) == 0 {
+		options.CaptureStatus = []StatusRange{{Min: 500, Max: 599}}
+	}
+	if options.HeaderAllowlist == nil {
+		options.HeaderAllowlist = defaultHeaderAllowlist
+	}
+	if options.RouteFunc == nil {
+		options.RouteFunc = func(r *http.Request) string {
+			return r.URL.Path
+		}
+	}
+	return &Handler{options: options}
+}
+
+func (h *Handler) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := h.client()
+		if client == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		hub := client.Hub().Clone()
+		hub.Scope().
+			SetTag("method", r.Method).
+			SetTag("route", h.options.RouteFunc(r))
+		if h.options.UserFunc != nil {
+			if user := h.options.UserFunc(r); user != nil {
+				hub.SetUser(*user)
+			}
+		}
+		ctx := errortracker.ContextWithHub(r.Context(), hub)
+		r = r.WithContext(ctx)
+
+		body := h.recordBody(r)
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+
+		defer func() {
+			if value := recover(); value != nil {
+				if value == http.ErrAbortHandler {
+					// net/http aborts the response quietly.
+					panic(value)
+				}
+
+				metadata := h.requestMetadata(r, body, start)
+				hub.Scope().SetExtraValues(metadata)
+				client.CapturePanic(ctx, value)
+
+				if h.options.Repanic {
+					if h.options.WaitForDelivery {
+						client.ForceFlush()
+					}
+					panic(value)
+				}
+				if !rw.wroteHeader {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+				return
+			}
+
+			if h.shouldCapture(rw.status) {
+				metadata := h.requestMetadata(r, body, start)
+				metadata["status_code"] = strconv.Itoa(rw.status)
+				route := h.options.RouteFunc(r)
+				title := fmt.Sprintf("HTTP %d: %s %s", rw.status, r.Method, route)
+				// The stack is the one of net/http, so group by route instead.
+				hub.CaptureEvent(ctx, title, types.LevelError, metadata,
+					errortracker.Fingerprint(core.DefaultFingerprint, r.Method, route))
+			}
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func (h *Handler) HandleFunc(next http.HandlerFunc) http.HandlerFunc {
+	return h.Handle(next).ServeHTTP
+}
+
+func (h *Handler) client() *errortracker.ErrorTrackerClient {
+	if h.options.Client != nil {
+		return h.options.Client
+	}
+	client, err := errortracker.Get()
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+func (h *Handler) shouldCapture(status int) bool {
+	for _, statusRange := range h.options.CaptureStatus {
+		if status >= statusRange.Min && status <= statusRange.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// recordBody keeps the first MaxRequestBodySize bytes the wrapped handler
+// reads from the body, without reading anything itself.
+func (h *Handler) recordBody(r *http.Request) *bodyBuffer {
+	if h.options.MaxRequestBodySize <= 0 || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	body := &bodyBuffer{limit: h.options.MaxRequestBodySize}
+	r.Body = readCloser{
+		Reader: io.TeeReader(r.Body, body),
+		Closer: r.Body,
+	}
+	return body
+}
+
+func (h *Handler) requestMetadata(r *http.Request, body *bodyBuffer, start time.Time) map[string]any {
+	metadata := map[string]any{
+		"method":     r.Method,
+		"route":      h.options.RouteFunc(r),
+		"url":        requestURL(r),
+		"client_ip":  ClientIP(r, h.options.TrustedProxies...),
+		"latency_ms": time.Since(start).Milliseconds(),
+	}
+
+	for _, name := range h.options.HeaderAllowlist {
+		name = http.CanonicalHeaderKey(name)
+		value := r.Header.Get(name)
+		if value == "" {
+			continue
+		}
+		if sensitiveHeaders[name] {
+			value = "[Filtered]"
+		}
+		metadata["header."+name] = value
+	}
+
+	if value, ok := requestBody(r, body); ok {
+		metadata["request_body"] = value
+	}
+
+	return metadata
+}
+
+// requestBody decodes JSON and form bodies, so the scrubbing rules for keys
+// such as "password" apply to their fields. A JSON or form body cut off at
+// the size limit cannot be decoded and is left out.
+func requestBody(r *http.Request, body *bodyBuffer) (any, bool) {
+	if body == nil || len(body.data) == 0 {
+		return nil, false
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		decoder := json.NewDecoder(bytes.NewReader(body.data))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, false
+		}
+		return value, true
+	case mediaType == "application/x-www-form-urlencoded":
+		if body.truncated {
+			return nil, false
+		}
+		form, err := url.ParseQuery(string(body.data))
+		if err != nil {
+			return nil, false
+		}
+		values := make(map[string]any, len(form))
+		for key, value := range form {
+			values[key] = strings.Join(value, ",")
+		}
+		return values, true
+	}
+	return string(body.data), true
+}
+
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	u := *r.URL
+	u.Scheme = scheme
+	u.Host = r.Host
+	u.RawQuery = ""
+	return u.Redacted()
+}
+
+// ClientIP returns the originating client address. The proxy headers are
+// only honoured when the connection comes from one of trustedProxies, and
+// X-Forwarded-For is read from the right, skipping the trusted hops, since
+// its leftmost entries are set by the client.
+func ClientIP(r *http.Request, trustedProxies ...netip.Prefix) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !isTrustedProxy(remote, trustedProxies) {
+		return remote
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if addr != "" && !isTrustedProxy(addr, trustedProxies) {
+			return addr
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-Ip")); realIP != "" {
+		return realIP
+	}
+	return remote
+}
+
+func isTrustedProxy(addr string, trustedProxies []netip.Prefix) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyBuffer keeps up to limit bytes written to it.
+type bodyBuffer struct {
+	limit     int64
+	data      []byte
+	truncated bool
+}
+
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if remaining := b.limit - int64(len(b.data)); int64(n) > remaining {
+		p = p[:max(remaining, 0)]
+		b.truncated = true
+	}
+	b.data = append(b.data, p...)
+	return n, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the first final status. Informational responses such
+// as 103 Early Hints may precede it, except 101 which ends the exchange.
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader && (status >= 200 || status == http.StatusSwitchingProtocols) {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("trackerhttp: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,398 @@
+package trackerhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/internal/trackertest"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type countingReader struct {
+	io.Reader
+	reads int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	r.reads++
+	return r.Reader.Read(p)
+}
+
+func newTestClient(t *testing.T) *errortracker.ErrorTrackerClient {
+	client, _ := trackertest.NewRecordingClient(t)
+	return client
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("should recover panics and answer 500", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/orders/1?token=secret", nil)
+		req.Header.Set("User-Agent", "test-agent")
+		req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("expected status 500, got %d", rec.Code)
+		}
+
+		recorded := events()
+		if len(recorded) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recorded))
+		}
+
+		event := recorded[0]
+		if event.Level != string(types.LevelFatal) {
+			t.Errorf("expected level FATAL, got %s", event.Level)
+		}
+		if event.Event.Mechanism == nil || event.Event.Mechanism.Type != "panic" {
+			t.Errorf("expected panic mechanism, got %+v", event.Event.Mechanism)
+		}
+		extra := event.Context.Extra
+		if extra["method"] != "GET" || extra["route"] != "/orders/1" {
+			t.Errorf("expected method and route, got %v", extra)
+		}
+		if extra["url"] != "http://example.com/orders/1" {
+			t.Errorf("expected url without query, got %s", extra["url"])
+		}
+		if ClientIP(req) != "192.0.2.1" {
+			t.Errorf("expected the connection address, got %s", ClientIP(req))
+		}
+		if extra["client_ip"] != "[Filtered]" {
+			t.Errorf("expected client ip to be scrubbed by default, got %s", extra["client_ip"])
+		}
+		if extra["header.User-Agent"] != "test-agent" {
+			t.Errorf("expected user agent header, got %v", extra)
+		}
+		if _, ok := extra["latency_ms"].(float64); !ok {
+			t.Errorf("expected a numeric latency_ms, got %#v", extra["latency_ms"])
+		}
+	})
+
+	t.Run("should honour proxy headers from trusted proxies only", func(t *testing.T) {
+		proxies := []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24"), netip.MustParsePrefix("10.0.0.0/8")}
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Forwarded-For", "198.51.100.9, 203.0.113.7, 10.0.0.1")
+		if ip := ClientIP(req, proxies...); ip != "203.0.113.7" {
+			t.Errorf("expected the last untrusted hop, got %s", ip)
+		}
+
+		req.RemoteAddr = "198.51.100.1:4000"
+		if ip := ClientIP(req, proxies...); ip != "198.51.100.1" {
+			t.Errorf("expected the connection address of an untrusted peer, got %s", ip)
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Real-Ip", "203.0.113.8")
+		if ip := ClientIP(req, proxies...); ip != "203.0.113.8" {
+			t.Errorf("expected X-Real-Ip, got %s", ip)
+		}
+	})
+
+	t.Run("should repanic when configured", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client, Repanic: true}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+
+		func() {
+			defer func() {
+				if value := recover(); value != "boom" {
+					t.Errorf("expected repanic with boom, got %v", value)
+				}
+			}()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+
+		if len(events()) != 1 {
+			t.Error("expected panic to be reported before repanic")
+		}
+	})
+
+	t.Run("should capture 5xx responses", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/checkout", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		recorded := events()
+		if len(recorded) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(recorded))
+		}
+		if recorded[0].Title != "HTTP 502: POST /checkout" && recorded[1].Title != "HTTP 502: POST /checkout" {
+			t.Errorf("unexpected titles %q, %q", recorded[0].Title, recorded[1].Title)
+		}
+	})
+
+	t.Run("should capture the status sent after informational responses", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusEarlyHints)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+		recorded := events()
+		if len(recorded) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recorded))
+		}
+		if recorded[0].Title != "HTTP 503: GET /orders" {
+			t.Errorf("expected the 503 to be captured, got %s", recorded[0].Title)
+		}
+	})
+
+	t.Run("should group status events by route", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		for _, path := range []string{"/a", "/b", "/a"} {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		fingerprints := make(map[string][]string)
+		for _, event := range events() {
+			fingerprints[event.Title] = append(fingerprints[event.Title], strings.Join(event.Fingerprint, ","))
+		}
+		a, b := fingerprints["HTTP 500: GET /a"], fingerprints["HTTP 500: GET /b"]
+		if len(a) != 2 || len(b) != 1 {
+			t.Fatalf("expected events for both routes, got %v", fingerprints)
+		}
+		if a[0] != a[1] {
+			t.Errorf("expected one fingerprint per route, got %v", a)
+		}
+		if a[0] == b[0] {
+			t.Errorf("expected routes to get different fingerprints, got %s", a[0])
+		}
+	})
+
+	t.Run("should ignore statuses outside capture ranges", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		options := Options{
+			Client:        client,
+			CaptureStatus: []StatusRange{{Min: 503, Max: 503}},
+		}
+		handler := New(options).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/down" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/error", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/down", nil))
+
+		recorded := events()
+		if len(recorded) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recorded))
+		}
+		if recorded[0].Context.Extra["status_code"] != "503" {
+			t.Errorf("expected status_code 503, got %s", recorded[0].Context.Extra["status_code"])
+		}
+	})
+
+	t.Run("should filter sensitive headers and cap body", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		options := Options{
+			Client:             client,
+			HeaderAllowlist:    []string{"Authorization", "X-Tenant"},
+			MaxRequestBodySize: 5,
+		}
+
+		var seenBody string
+		handler := New(options).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			seenBody = string(body)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("0123456789"))
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Tenant", "acme")
+		req.Header.Set("User-Agent", "not-allowlisted")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if seenBody != "0123456789" {
+			t.Errorf("expected handler to read full body, got %q", seenBody)
+		}
+
+		extra := events()[0].Context.Extra
+		if extra["header.Authorization"] != "[Filtered]" {
+			t.Errorf("expected authorization to be filtered, got %s", extra["header.Authorization"])
+		}
+		if extra["header.X-Tenant"] != "acme" {
+			t.Errorf("expected allowlisted header, got %v", extra)
+		}
+		if _, ok := extra["header.User-Agent"]; ok {
+			t.Error("expected non-allowlisted header to be dropped")
+		}
+		if extra["request_body"] != "01234" {
+			t.Errorf("expected capped body, got %q", extra["request_body"])
+		}
+	})
+
+	t.Run("should let net/http abort responses", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		var repanicked any
+		func() {
+			defer func() {
+				repanicked = recover()
+			}()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+		}()
+
+		if repanicked != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler, got %v", repanicked)
+		}
+		if recorded := events(); len(recorded) != 0 {
+			t.Errorf("expected no events, got %d", len(recorded))
+		}
+	})
+
+	t.Run("should only record the body the handler reads", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client, MaxRequestBodySize: 1024}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		body := &countingReader{Reader: strings.NewReader("upload")}
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/uploads", body))
+
+		if body.reads != 0 {
+			t.Errorf("expected the body to be left unread, got %d reads", body.reads)
+		}
+		if _, ok := events()[0].Context.Extra["request_body"]; ok {
+			t.Error("expected no request body")
+		}
+	})
+
+	t.Run("should decode JSON and form bodies for scrubbing", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client, MaxRequestBodySize: 1024}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"user":"jane","password":"hunter2"}`))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		req = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("user=jane&password=hunter2"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		for _, event := range events() {
+			body, ok := event.Context.Extra["request_body"].(map[string]any)
+			if !ok {
+				t.Fatalf("expected a decoded body, got %v", event.Context.Extra["request_body"])
+			}
+			if body["user"] != "jane" || body["password"] != "[Filtered]" {
+				t.Errorf("expected password to be filtered, got %v", body)
+			}
+		}
+	})
+
+	t.Run("should leave out JSON bodies cut off at the limit", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client, MaxRequestBodySize: 10}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"password":"hunter2"}`))
+		req.Header.Set("Content-Type", "application/json")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if body, ok := events()[0].Context.Extra["request_body"]; ok {
+			t.Errorf("expected no request body, got %v", body)
+		}
+	})
+
+	t.Run("should put a request scoped hub in the context", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		handler := New(Options{Client: client}).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hub := errortracker.HubFromContext(r.Context())
+			if hub == nil {
+				t.Fatal("expected hub in request context")
+			}
+			hub.Scope().SetTag("tenant", "acme")
+			errortracker.ErrorCtx(r.Context(), io.ErrUnexpectedEOF, types.LevelError, nil)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/upload", nil))
+		client.Error(io.EOF, types.LevelError, nil)
+
+		recorded := events()
+		if len(recorded) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(recorded))
+		}
+		for _, event := range recorded {
+			tagged := false
+			for _, tag := range event.Context.Tags {
+				if tag == "tenant:acme" {
+					tagged = true
+				}
+			}
+			if event.Title == io.EOF.Error() && tagged {
+				t.Error("expected request scope not to leak into the client scope")
+			}
+			if event.Title == io.ErrUnexpectedEOF.Error() && !tagged {
+				t.Errorf("expected request tag, got %v", event.Context.Tags)
+			}
+		}
+	})
+	t.Run("should set the user from the extractor", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		options := Options{
+			Client: client,
+			UserFunc: func(r *http.Request) *types.User {
+				id := r.Header.Get("X-User-Id")
+				if id == "" {
+					return nil
+				}
+				return &types.User{ID: id, Segment: "enterprise"}
+			},
+		}
+		handler := New(options).Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set("X-User-Id", "u-42")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+		recorded := events()
+		if len(recorded) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(recorded))
+		}
+		var users []*types.User
+		for _, event := range recorded {
+			if event.Context.User != nil {
+				users = append(users, event.Context.User)
+			}
+		}
+		if len(users) != 1 || users[0].ID != "u-42" || users[0].Segment != "enterprise" {
+			t.Errorf("expected one extracted user, got %+v", users)
+		}
+	})
+}
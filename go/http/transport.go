@@ -0,0 +1,58 @@
+package trackerhttp
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/types"
+)
+
+// Transport records a breadcrumb for every outgoing request on the hub found
+// in the request context, or on the default instance.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+
+	breadcrumb := types.Breadcrumb{
+		Category: "http",
+		Message:  req.Method + " " + req.URL.Redacted(),
+		Level:    types.LevelInfo,
+		Data: map[string]string{
+			"method":      req.Method,
+			"url":         req.URL.Redacted(),
+			"duration_ms": strconv.FormatInt(time.Since(start).Milliseconds(), 10),
+		},
+	}
+
+	switch {
+	case err != nil:
+		breadcrumb.Level = types.LevelError
+		breadcrumb.Data["error"] = err.Error()
+	case resp.StatusCode >= 500:
+		breadcrumb.Level = types.LevelError
+	case resp.StatusCode >= 400:
+		breadcrumb.Level = types.LevelWarning
+	}
+	if resp != nil {
+		breadcrumb.Data["status_code"] = strconv.Itoa(resp.StatusCode)
+	}
+
+	errortracker.AddBreadcrumbCtx(req.Context(), breadcrumb)
+
+	return resp, err
+}
@@ -0,0 +1,125 @@
+package errortracker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type hubContextKey struct{}
+
+type Hub struct {
+	client *ErrorTrackerClient
+	mu     sync.RWMutex
+	stack  []*core.Scope
+}
+
+func NewHub(client *ErrorTrackerClient, scope *core.Scope) *Hub {
+	if scope == nil {
+		scope = core.NewScope()
+	}
+	return &Hub{
+		client: client,
+		stack:  []*core.Scope{scope},
+	}
+}
+
+func ContextWithHub(ctx context.Context, hub *Hub) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, hubContextKey{}, hub)
+}
+
+func HubFromContext(ctx context.Context) *Hub {
+	if ctx == nil {
+		return nil
+	}
+	hub, _ := ctx.Value(hubContextKey{}).(*Hub)
+	return hub
+}
+
+func (h *Hub) Client() *ErrorTrackerClient {
+	return h.client
+}
+
+func (h *Hub) Scope() *core.Scope {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.stack[len(h.stack)-1]
+}
+
+func (h *Hub) PushScope() *core.Scope {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	scope := h.stack[len(h.stack)-1].Clone()
+	h.stack = append(h.stack, scope)
+	return scope
+}
+
+// PopScope discards the innermost scope. The root scope is never popped.
+func (h *Hub) PopScope() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.stack) > 1 {
+		h.stack = h.stack[:len(h.stack)-1]
+	}
+}
+
+func (h *Hub) WithScope(f func(scope *core.Scope)) {
+	scope := h.PushScope()
+	defer h.PopScope()
+	f(scope)
+}
+
+func (h *Hub) ConfigureScope(f func(scope *core.Scope)) {
+	f(h.Scope())
+}
+
+// Clone returns a hub bound to the same client with a copy of the current
+// scope, meant to be handed to a new goroutine or request.
+func (h *Hub) Clone() *Hub {
+	return NewHub(h.client, h.Scope().Clone())
+}
+
+func (h *Hub) AddBreadcrumb(breadcrumb types.Breadcrumb) *Hub {
+	h.Scope().AddBreadcrumb(breadcrumb, h.client.config.MaxBreadcrumbs)
+	return h
+}
+
+func (h *Hub) SetUser(user types.User) *Hub {
+	h.Scope().SetUser(user)
+	return h
+}
+
+func (h *Hub) Error(err error, level types.EventLevel, metadata map[string]string, options ...CaptureOption) *Hub {
+	return h.ErrorCtx(context.Background(), err, level, metadata, options...)
+}
+
+func (h *Hub) ErrorCtx(ctx context.Context, err error, level types.EventLevel, metadata map[string]string, options ...CaptureOption) *Hub {
+	h.client.captureError(ctx, h.Scope(), err, level, stringMetadata(metadata), options)
+	return h
+}
+
+func (h *Hub) Event(title string, level types.EventLevel, metadata map[string]string, options ...CaptureOption) *Hub {
+	return h.EventCtx(context.Background(), title, level, metadata, options...)
+}
+
+func (h *Hub) EventCtx(ctx context.Context, title string, level types.EventLevel, metadata map[string]string, options ...CaptureOption) *Hub {
+	h.client.captureEvent(ctx, h.Scope(), title, level, stringMetadata(metadata), options)
+	return h
+}
+
+func (h *Hub) CaptureError(ctx context.Context, err error, level types.EventLevel, data map[string]any, options ...CaptureOption) *Hub {
+	h.client.captureError(ctx, h.Scope(), err, level, data, options)
+	return h
+}
+
+func (h *Hub) CaptureEvent(ctx context.Context, title string, level types.EventLevel, data map[string]any, options ...CaptureOption) *Hub {
+	h.client.captureEvent(ctx, h.Scope(), title, level, data, options)
+	return h
+}
@@ -0,0 +1,183 @@
+package errortracker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestHubScope(t *testing.T) {
+	t.Run("should apply client scope to events", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.SetTag("service", "billing")
+			scope.SetExtra("region", "eu-west-1")
+		})
+
+		client.Error(errors.New("test error"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if !hasTag(event.Context.Tags, "service:billing") {
+			t.Errorf("expected service tag, got %v", event.Context.Tags)
+		}
+		if event.Context.Extra["region"] != "eu-west-1" {
+			t.Errorf("expected region extra, got %v", event.Context.Extra)
+		}
+	})
+
+	t.Run("should apply level override and fingerprint", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Hub().WithScope(func(scope *core.Scope) {
+			scope.SetLevel(types.LevelWarning)
+			scope.SetFingerprint([]string{"orders", "timeout"})
+			client.Hub().Error(errors.New("test error"), "", nil)
+		})
+
+		event := lastQueuedEvent(t, client)
+		if event.Level != string(types.LevelWarning) {
+			t.Errorf("expected level WARNING, got %s", event.Level)
+		}
+		if len(event.Fingerprint) != 2 || event.Fingerprint[0] != "orders" {
+			t.Errorf("expected fingerprint to be set, got %v", event.Fingerprint)
+		}
+	})
+
+	t.Run("should keep an explicit level over the scope level", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.SetLevel(types.LevelInfo)
+		})
+
+		client.Error(errors.New("test error"), types.LevelError, nil)
+		if event := lastQueuedEvent(t, client); event.Level != string(types.LevelError) {
+			t.Errorf("expected level ERROR, got %s", event.Level)
+		}
+
+		client.CapturePanic(context.Background(), "boom")
+		if event := lastQueuedEvent(t, client); event.Level != string(types.LevelFatal) {
+			t.Errorf("expected level FATAL, got %s", event.Level)
+		}
+	})
+
+	t.Run("should default to ERROR without a level", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(errors.New("test error"), "", nil)
+
+		if event := lastQueuedEvent(t, client); event.Level != string(types.LevelError) {
+			t.Errorf("expected level ERROR, got %s", event.Level)
+		}
+	})
+
+	t.Run("should discard pushed scope on pop", func(t *testing.T) {
+		client := newContextTestClient(t)
+		hub := client.Hub()
+
+		scope := hub.PushScope()
+		scope.SetTag("temporary", "yes")
+		hub.PopScope()
+
+		hub.Error(errors.New("test error"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if hasTag(event.Context.Tags, "temporary:yes") {
+			t.Errorf("expected popped scope to be discarded, got %v", event.Context.Tags)
+		}
+	})
+
+	t.Run("should never pop root scope", func(t *testing.T) {
+		client := newContextTestClient(t)
+		hub := client.Hub()
+
+		root := hub.Scope()
+		hub.PopScope()
+		hub.PopScope()
+
+		if hub.Scope() != root {
+			t.Error("expected root scope to remain")
+		}
+	})
+
+	t.Run("should prefer context scope over hub scope", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.SetExtra("source", "hub")
+		})
+
+		ctx := WithExtra(context.Background(), map[string]string{"source": "context"})
+		client.ErrorCtx(ctx, errors.New("test error"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if event.Context.Extra["source"] != "context" {
+			t.Errorf("expected context value, got %s", event.Context.Extra["source"])
+		}
+	})
+}
+
+func TestHubClone(t *testing.T) {
+	t.Run("should isolate cloned hubs", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.SetTag("shared", "yes")
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				hub := client.Hub().Clone()
+				hub.Scope().SetExtra("worker", string(rune('a'+worker)))
+				hub.Event("done", types.LevelInfo, nil)
+			}(i)
+		}
+		wg.Wait()
+
+		client.queueMu.Lock()
+		defer client.queueMu.Unlock()
+
+		if len(client.eventQueue) != 10 {
+			t.Fatalf("expected queue length to be 10, got %d", len(client.eventQueue))
+		}
+
+		seen := map[any]bool{}
+		for _, event := range client.eventQueue {
+			if !hasTag(event.Context.Tags, "shared:yes") {
+				t.Errorf("expected shared tag, got %v", event.Context.Tags)
+			}
+			seen[event.Context.Extra["worker"]] = true
+		}
+		if len(seen) != 10 {
+			t.Errorf("expected 10 distinct workers, got %d", len(seen))
+		}
+	})
+
+	t.Run("should use hub from context", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		hub := client.Hub().Clone()
+		hub.Scope().SetTag("request", "r-1")
+		ctx := ContextWithHub(context.Background(), hub)
+
+		if err := ErrorCtx(ctx, errors.New("test error"), types.LevelError, nil); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		event := lastQueuedEvent(t, client)
+		if !hasTag(event.Context.Tags, "request:r-1") {
+			t.Errorf("expected request tag, got %v", event.Context.Tags)
+		}
+
+		client.Error(errors.New("root error"), types.LevelError, nil)
+		event = lastQueuedEvent(t, client)
+		if hasTag(event.Context.Tags, "request:r-1") {
+			t.Errorf("expected root hub to stay untouched, got %v", event.Context.Tags)
+		}
+	})
+}
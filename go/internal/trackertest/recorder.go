@@ -0,0 +1,80 @@
+// Package trackertest records the events a client delivers, for the tests of
+// the integration packages.
+package trackertest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type webhookRecorder struct {
+	mu     sync.Mutex
+	events []types.EventIssue
+}
+
+func (rec *webhookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload types.TransportPayload
+	json.NewDecoder(r.Body).Decode(&payload)
+
+	compressed, _ := base64.StdEncoding.DecodeString(payload.Event)
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	data, _ := io.ReadAll(reader)
+
+	var event types.EventIssue
+	json.Unmarshal(data, &event)
+
+	rec.mu.Lock()
+	rec.events = append(rec.events, event)
+	rec.mu.Unlock()
+}
+
+// NewRecordingClient returns a client that delivers to a local webhook, and a
+// function that flushes the client and returns every event received so far.
+func NewRecordingClient(t *testing.T) (*errortracker.ErrorTrackerClient, func() []types.EventIssue) {
+	t.Helper()
+
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	config := &types.ClientConfig{
+		WebhookURL:    server.URL,
+		LicenseID:     "test-license",
+		LicenseDevice: "test-device",
+		Enabled:       true,
+		MaxRetries:    3,
+		Timeout:       10 * time.Second,
+		FlushInterval: 5 * time.Second,
+		MaxQueueSize:  50,
+	}
+
+	client, err := errortracker.NewClient(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	events := func() []types.EventIssue {
+		if err := client.ForceFlush(); err != nil {
+			t.Fatalf("expected no flush error, got %v", err)
+		}
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		return append([]types.EventIssue(nil), recorder.events...)
+	}
+	return client, events
+}
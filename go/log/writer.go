@@ -0,0 +1,51 @@
+package trackerlog
+
+import (
+	"bytes"
+	"io"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/types"
+)
+
+// Writer turns every line written by a standard library logger into a
+// breadcrumb and forwards the original output to Out when set.
+type Writer struct {
+	Client *errortracker.ErrorTrackerClient
+	Out    io.Writer
+	Level  types.EventLevel
+}
+
+func NewWriter(client *errortracker.ErrorTrackerClient, out io.Writer) *Writer {
+	return &Writer{
+		Client: client,
+		Out:    out,
+		Level:  types.LevelInfo,
+	}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		w.addBreadcrumb(types.Breadcrumb{
+			Category: "log",
+			Message:  string(line),
+			Level:    w.Level,
+		})
+	}
+
+	if w.Out != nil {
+		return w.Out.Write(p)
+	}
+	return len(p), nil
+}
+
+func (w *Writer) addBreadcrumb(breadcrumb types.Breadcrumb) {
+	if w.Client != nil {
+		w.Client.AddBreadcrumb(breadcrumb)
+		return
+	}
+	errortracker.AddBreadcrumb(breadcrumb)
+}
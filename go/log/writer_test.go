@@ -0,0 +1,48 @@
+package trackerlog
+
+import (
+	"bytes"
+	"log"
+	"testing"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestWriter(t *testing.T) {
+	t.Run("should record log lines as breadcrumbs", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+		}
+
+		client, err := errortracker.NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var out bytes.Buffer
+		logger := log.New(NewWriter(client, &out), "", 0)
+		logger.Println("cache miss")
+		logger.Println("retrying request")
+
+		if out.String() != "cache miss\nretrying request\n" {
+			t.Errorf("expected output to be forwarded, got %q", out.String())
+		}
+
+		breadcrumbs := client.Hub().Scope().Breadcrumbs()
+		if len(breadcrumbs) != 2 {
+			t.Fatalf("expected 2 breadcrumbs, got %d", len(breadcrumbs))
+		}
+		if breadcrumbs[0].Category != "log" || breadcrumbs[0].Message != "cache miss" {
+			t.Errorf("unexpected breadcrumb %+v", breadcrumbs[0])
+		}
+	})
+}
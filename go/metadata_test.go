@@ -0,0 +1,233 @@
+package errortracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type orderLine struct {
+	SKU      string  `json:"sku"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+	Internal string  `json:"-"`
+	note     string
+}
+
+type orderStatus int
+
+func (s orderStatus) String() string {
+	return [...]string{"pending", "paid"}[s]
+}
+
+type node struct {
+	Name string `json:"name"`
+	Next *node  `json:"next"`
+}
+
+func TestStructuredMetadata(t *testing.T) {
+	t.Run("should keep typed values", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		placed := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+		client.CaptureError(context.Background(), errors.New("checkout failed"), types.LevelError, map[string]any{
+			"attempt":  3,
+			"retry":    true,
+			"amount":   19.99,
+			"placed":   placed,
+			"timeout":  1500 * time.Millisecond,
+			"status":   orderStatus(1),
+			"cause":    &paymentError{code: 402},
+			"lines":    []orderLine{{SKU: "A-1", Quantity: 2, Price: 9.5, Internal: "x", note: "y"}},
+			"customer": map[string]any{"tier": "gold", "orders": []int{1, 2}},
+		})
+
+		extra := lastQueuedEvent(t, client).Context.Extra
+		if extra["attempt"] != 3 || extra["retry"] != true || extra["amount"] != 19.99 {
+			t.Errorf("expected primitives to be preserved, got %v", extra)
+		}
+		if extra["placed"] != "2024-03-01T11:00:00Z" {
+			t.Errorf("expected UTC timestamp, got %v", extra["placed"])
+		}
+		if extra["timeout"] != "1.5s" || extra["status"] != "paid" || extra["cause"] != "payment failed" {
+			t.Errorf("expected formatted values, got %v", extra)
+		}
+
+		lines, ok := extra["lines"].([]any)
+		if !ok || len(lines) != 1 {
+			t.Fatalf("expected one line, got %#v", extra["lines"])
+		}
+		line := lines[0].(map[string]any)
+		if line["sku"] != "A-1" || line["quantity"] != 2 || len(line) != 3 {
+			t.Errorf("expected json field names without hidden fields, got %v", line)
+		}
+
+		customer := extra["customer"].(map[string]any)
+		if customer["tier"] != "gold" || len(customer["orders"].([]any)) != 2 {
+			t.Errorf("expected nested map, got %v", customer)
+		}
+	})
+
+	t.Run("should replace values JSON cannot encode", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.CaptureEvent(context.Background(), "odd values", types.LevelInfo, map[string]any{
+			"nan":     math.NaN(),
+			"inf":     math.Inf(1),
+			"channel": make(chan int),
+			"func":    func() {},
+			"invalid": "caf\xe9",
+			"nil":     (*orderLine)(nil),
+		})
+
+		event := lastQueuedEvent(t, client)
+		extra := event.Context.Extra
+		if extra["nan"] != "NaN" || extra["inf"] != "+Inf" {
+			t.Errorf("expected float placeholders, got %v", extra)
+		}
+		if extra["channel"] != "[chan int]" || extra["func"] != "[func()]" {
+			t.Errorf("expected type placeholders, got %v", extra)
+		}
+		if extra["invalid"] != "caf�" || extra["nil"] != nil {
+			t.Errorf("expected sanitized string and nil, got %v", extra)
+		}
+		if _, err := json.Marshal(event); err != nil {
+			t.Errorf("expected event to marshal, got %v", err)
+		}
+	})
+
+	t.Run("should cut cycles and deep nesting", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		loop := &node{Name: "a"}
+		loop.Next = &node{Name: "b", Next: loop}
+		self := map[string]any{}
+		self["self"] = self
+
+		deep := map[string]any{}
+		current := deep
+		for i := 0; i < 10; i++ {
+			next := map[string]any{}
+			current["child"] = next
+			current = next
+		}
+
+		client.CaptureEvent(context.Background(), "cycles", types.LevelInfo, map[string]any{
+			"loop": loop,
+			"self": self,
+			"deep": deep,
+		})
+
+		extra := lastQueuedEvent(t, client).Context.Extra
+		next := extra["loop"].(map[string]any)["next"].(map[string]any)
+		if next["next"] != "[Circular]" {
+			t.Errorf("expected circular marker, got %v", next["next"])
+		}
+		if extra["self"].(map[string]any)["self"] != "[Circular]" {
+			t.Errorf("expected circular marker, got %v", extra["self"])
+		}
+
+		level := extra["deep"]
+		for i := 0; i < 5; i++ {
+			level = level.(map[string]any)["child"]
+		}
+		if level != "[MaxDepth]" {
+			t.Errorf("expected depth marker, got %v", level)
+		}
+	})
+
+	t.Run("should honour size limits", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+			MetadataLimits: types.MetadataLimits{
+				MaxItems:        3,
+				MaxStringLength: 32,
+			},
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		client.CaptureEvent(context.Background(), "limits", types.LevelInfo, map[string]any{
+			"items":       []string{"a", "b", "c", "d", "e"},
+			"description": "a description longer than the limit",
+			"region":      "eu",
+			"zone":        "eu-1",
+		})
+
+		extra := lastQueuedEvent(t, client).Context.Extra
+		items := extra["items"].([]any)
+		if len(items) != 3 || items[2] != "[Truncated: 3 more items]" {
+			t.Errorf("expected truncated slice, got %v", items)
+		}
+		if extra["description"] != "a description longer than the…" {
+			t.Errorf("expected truncated string, got %v", extra["description"])
+		}
+		if extra["[Truncated]"] != "2 more keys" || extra["region"] != nil {
+			t.Errorf("expected truncated keys, got %v", extra)
+		}
+	})
+
+	t.Run("should truncate strings on a rune boundary", func(t *testing.T) {
+		extra := core.SanitizeExtra(map[string]any{"text": "héllo"}, types.MetadataLimits{MaxStringLength: 5})
+		if extra["text"] != "h…" {
+			t.Errorf("expected truncated string, got %q", extra["text"])
+		}
+	})
+
+	t.Run("should sanitize values set by processors and scopes", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.SetExtra("started", time.Unix(0, 0))
+		})
+		client.AddEventProcessor(func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+			event.Context.Extra["handler"] = func() {}
+			return event
+		})
+
+		client.Error(errors.New("boom"), types.LevelError, map[string]string{"orderId": "42"})
+
+		extra := lastQueuedEvent(t, client).Context.Extra
+		if extra["orderId"] != "42" || extra["started"] != "1970-01-01T00:00:00Z" {
+			t.Errorf("expected string and scope metadata, got %v", extra)
+		}
+		if extra["handler"] != "[func()]" {
+			t.Errorf("expected processor value to be sanitized, got %v", extra["handler"])
+		}
+	})
+
+	t.Run("should scrub nested metadata", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.CaptureEvent(context.Background(), "login", types.LevelInfo, map[string]any{
+			"request": map[string]any{
+				"headers":  map[string]any{"Authorization": "Bearer abc"},
+				"contacts": []any{"ada@example.com"},
+			},
+		})
+
+		request := lastQueuedEvent(t, client).Context.Extra["request"].(map[string]any)
+		if request["headers"].(map[string]any)["Authorization"] != core.Filtered {
+			t.Errorf("expected nested key to be filtered, got %v", request["headers"])
+		}
+		if contact := request["contacts"].([]any)[0].(string); strings.Contains(contact, "ada@") {
+			t.Errorf("expected nested email to be scrubbed, got %s", contact)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package errortracker
+
+import (
+	"context"
+
+	"github.com/royaltics/tracker-go/core"
+)
+
+// CaptureOption adjusts a single captured event. Its values take priority
+// over the context and hub scopes.
+type CaptureOption func(scope *core.Scope)
+
+// Fingerprint overrides the grouping key of the event. Use
+// core.DefaultFingerprint to extend the computed fingerprint instead of
+// replacing it.
+func Fingerprint(parts ...string) CaptureOption {
+	return func(scope *core.Scope) {
+		scope.SetFingerprint(parts)
+	}
+}
+
+func withOptions(ctx context.Context, options []CaptureOption) context.Context {
+	if len(options) == 0 {
+		return ctx
+	}
+	return withScope(ctx, func(scope *core.Scope) {
+		for _, option := range options {
+			option(scope)
+		}
+	})
+}
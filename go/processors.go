@@ -0,0 +1,108 @@
+package errortracker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+var (
+	globalProcessorsMu sync.RWMutex
+	globalProcessors   []types.EventProcessor
+)
+
+// AddGlobalEventProcessor registers a processor run on the events of every
+// client, before the client and scope processors.
+func AddGlobalEventProcessor(processor types.EventProcessor) {
+	globalProcessorsMu.Lock()
+	globalProcessors = append(globalProcessors, processor)
+	globalProcessorsMu.Unlock()
+}
+
+func (c *ErrorTrackerClient) AddEventProcessor(processor types.EventProcessor) *ErrorTrackerClient {
+	c.processorsMu.Lock()
+	c.processors = append(c.processors, processor)
+	c.processorsMu.Unlock()
+	return c
+}
+
+// send samples a built event and hands it to the dedupe window, or straight
+// to emit when deduplication is disabled.
+func (c *ErrorTrackerClient) send(ctx context.Context, scope *core.Scope, event types.EventIssue, hint types.Hint) {
+	c.stats.captured.Add(1)
+
+	if !c.sample(&event) {
+		c.stats.sampled.Add(1)
+		return
+	}
+
+	hint.Context = ctx
+	processors := c.eventProcessors(ctx, scope)
+	emit := func(event types.EventIssue) {
+		c.emit(event, hint, processors)
+	}
+
+	if c.dedupe != nil {
+		if c.dedupe.add(event, emit) {
+			c.stats.suppressed.Add(1)
+		}
+		return
+	}
+	emit(event)
+}
+
+// emit runs the processor chain, applies the rate limits and queues the
+// scrubbed result. Events dropped by a processor take no rate limit tokens.
+func (c *ErrorTrackerClient) emit(event types.EventIssue, hint types.Hint, processors []types.EventProcessor) {
+	processed := &event
+	for _, processor := range processors {
+		processed = c.runProcessor(processor, processed, hint)
+		if processed == nil {
+			c.stats.dropped.Add(1)
+			return
+		}
+	}
+
+	if c.rateLimiter != nil && !c.rateLimiter.allow(processed, time.Now()) {
+		c.stats.rateLimited.Add(1)
+		return
+	}
+
+	c.eventBuilder.Sanitize(processed)
+	c.scrubber.Scrub(processed)
+	c.enqueue(*processed)
+}
+
+// eventProcessors returns the chain in order: global, client, hub scope,
+// context scope and finally BeforeSend. It is resolved at capture time so a
+// held duplicate is processed with the scopes it was captured with.
+func (c *ErrorTrackerClient) eventProcessors(ctx context.Context, scope *core.Scope) []types.EventProcessor {
+	globalProcessorsMu.RLock()
+	processors := append([]types.EventProcessor(nil), globalProcessors...)
+	globalProcessorsMu.RUnlock()
+
+	c.processorsMu.RLock()
+	processors = append(processors, c.processors...)
+	c.processorsMu.RUnlock()
+
+	processors = append(processors, core.ScopeEventProcessors(core.ScopeFromContext(ctx), scope)...)
+	if c.config.BeforeSend != nil {
+		processors = append(processors, c.config.BeforeSend)
+	}
+	return processors
+}
+
+// runProcessor isolates panics of user code: a processor that panics is
+// skipped and the event continues down the chain unchanged by it.
+func (c *ErrorTrackerClient) runProcessor(processor types.EventProcessor, event *types.EventIssue, hint types.Hint) (result *types.EventIssue) {
+	defer func() {
+		if recover() != nil {
+			c.stats.processorPanics.Add(1)
+			result = event
+		}
+	}()
+	return processor(event, hint)
+}
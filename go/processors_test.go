@@ -0,0 +1,208 @@
+package errortracker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+func queueLength(client *ErrorTrackerClient) int {
+	client.queueMu.Lock()
+	defer client.queueMu.Unlock()
+	return len(client.eventQueue)
+}
+
+func TestEventProcessors(t *testing.T) {
+	t.Run("should run BeforeSend with the original error", func(t *testing.T) {
+		cause := &paymentError{code: 402}
+		var hint types.Hint
+
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+			BeforeSend: func(event *types.EventIssue, h types.Hint) *types.EventIssue {
+				hint = h
+				event.Title = "rewritten"
+				return event
+			},
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		client.Error(cause, types.LevelError, nil)
+
+		if hint.OriginalError != cause {
+			t.Errorf("expected original error in hint, got %v", hint.OriginalError)
+		}
+		if hint.Context == nil {
+			t.Error("expected context in hint")
+		}
+		if event := lastQueuedEvent(t, client); event.Title != "rewritten" {
+			t.Errorf("expected rewritten title, got %s", event.Title)
+		}
+	})
+
+	t.Run("should expose the panic value", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		var hint types.Hint
+		client.AddEventProcessor(func(event *types.EventIssue, h types.Hint) *types.EventIssue {
+			hint = h
+			return event
+		})
+
+		func() {
+			defer client.Recover()
+			panicWithString()
+		}()
+
+		if hint.PanicValue != "boom" {
+			t.Errorf("expected panic value boom, got %v", hint.PanicValue)
+		}
+		if hint.OriginalError != nil {
+			t.Errorf("expected no original error for string panic, got %v", hint.OriginalError)
+		}
+	})
+
+	t.Run("should run processors in order", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		var order []string
+		record := func(name string) types.EventProcessor {
+			return func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+				order = append(order, name)
+				return event
+			}
+		}
+
+		client.AddEventProcessor(record("client"))
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.AddEventProcessor(record("hub"))
+		})
+		ctx := withScope(context.Background(), func(scope *core.Scope) {
+			scope.AddEventProcessor(record("context"))
+		})
+		client.config.BeforeSend = record("before_send")
+
+		client.ErrorCtx(ctx, errors.New("boom"), types.LevelError, nil)
+
+		want := []string{"client", "hub", "context", "before_send"}
+		if len(order) != len(want) {
+			t.Fatalf("expected %v, got %v", want, order)
+		}
+		for i := range want {
+			if order[i] != want[i] {
+				t.Errorf("expected %v, got %v", want, order)
+				break
+			}
+		}
+	})
+
+	t.Run("should not repeat processors and breadcrumbs of a cloned scope", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		calls := 0
+		client.ConfigureScope(func(scope *core.Scope) {
+			scope.AddEventProcessor(func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+				calls++
+				return event
+			})
+		})
+		client.AddBreadcrumb(types.Breadcrumb{Message: "loaded cart"})
+		ctx := core.ContextWithScope(context.Background(), client.Hub().Scope().Clone())
+
+		client.ErrorCtx(ctx, errors.New("boom"), types.LevelError, nil)
+
+		if calls != 1 {
+			t.Errorf("expected the processor to run once, got %d", calls)
+		}
+		if event := lastQueuedEvent(t, client); len(event.Breadcrumbs) != 1 {
+			t.Errorf("expected 1 breadcrumb, got %v", event.Breadcrumbs)
+		}
+	})
+
+	t.Run("should drop events and count them", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		var reached bool
+		client.AddEventProcessor(func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+			if event.Level == string(types.LevelDebug) {
+				return nil
+			}
+			return event
+		})
+		client.config.BeforeSend = func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+			reached = event.Level == string(types.LevelDebug)
+			return event
+		}
+
+		client.Event("noise", types.LevelDebug, nil)
+		client.Event("kept", types.LevelInfo, nil)
+
+		if reached {
+			t.Error("expected chain to stop after a dropped event")
+		}
+		if queueLength(client) != 1 {
+			t.Errorf("expected 1 queued event, got %d", queueLength(client))
+		}
+
+		stats := client.Stats()
+		if stats.EventsCaptured != 2 || stats.EventsDropped != 1 {
+			t.Errorf("expected 2 captured and 1 dropped, got %+v", stats)
+		}
+	})
+
+	t.Run("should isolate panicking processors", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.AddEventProcessor(func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+			panic("processor bug")
+		})
+		client.config.BeforeSend = func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+			event.Title = "after panic"
+			return event
+		}
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		if event := lastQueuedEvent(t, client); event.Title != "after panic" {
+			t.Errorf("expected chain to continue, got %s", event.Title)
+		}
+		if stats := client.Stats(); stats.ProcessorPanics != 1 {
+			t.Errorf("expected 1 processor panic, got %d", stats.ProcessorPanics)
+		}
+	})
+
+	t.Run("should run global processors for every client", func(t *testing.T) {
+		defer func() {
+			globalProcessorsMu.Lock()
+			globalProcessors = nil
+			globalProcessorsMu.Unlock()
+		}()
+
+		AddGlobalEventProcessor(func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+			event.Context.Tags = append(event.Context.Tags, "global:yes")
+			return event
+		})
+
+		for _, client := range []*ErrorTrackerClient{newContextTestClient(t), newContextTestClient(t)} {
+			client.Error(errors.New("boom"), types.LevelError, nil)
+			if event := lastQueuedEvent(t, client); !hasTag(event.Context.Tags, "global:yes") {
+				t.Errorf("expected global processor tag, got %v", event.Context.Tags)
+			}
+		}
+	})
+}
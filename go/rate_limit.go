@@ -0,0 +1,128 @@
+package errortracker
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+// maxRateLimitBuckets bounds the per-fingerprint buckets; full buckets are
+// evicted past it since they behave exactly like new ones.
+const maxRateLimitBuckets = 10000
+
+type tokenBucket struct {
+	limit  types.RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit types.RateLimit, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		limit:  limit,
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.EventsPerSecond)
+		b.last = now
+	}
+}
+
+func (b *tokenBucket) full() bool {
+	return b.tokens >= float64(b.limit.Burst)
+}
+
+type rateLimiter struct {
+	mu                 sync.Mutex
+	fingerprint        types.RateLimit
+	levels             map[types.EventLevel]types.RateLimit
+	fingerprintBuckets map[string]*tokenBucket
+	levelBuckets       map[types.EventLevel]*tokenBucket
+}
+
+// newRateLimiter returns nil when no limit is configured.
+func newRateLimiter(fingerprint types.RateLimit, levels map[types.EventLevel]types.RateLimit) *rateLimiter {
+	limiter := &rateLimiter{
+		fingerprint:        withDefaultBurst(fingerprint),
+		levels:             make(map[types.EventLevel]types.RateLimit),
+		fingerprintBuckets: make(map[string]*tokenBucket),
+		levelBuckets:       make(map[types.EventLevel]*tokenBucket),
+	}
+	for level, limit := range levels {
+		if limit.EventsPerSecond > 0 {
+			limiter.levels[level] = withDefaultBurst(limit)
+		}
+	}
+
+	if limiter.fingerprint.EventsPerSecond <= 0 && len(limiter.levels) == 0 {
+		return nil
+	}
+	return limiter
+}
+
+func withDefaultBurst(limit types.RateLimit) types.RateLimit {
+	if limit.Burst < 1 {
+		limit.Burst = int(math.Max(1, math.Ceil(limit.EventsPerSecond)))
+	}
+	return limit
+}
+
+// allow takes a token from the fingerprint and the level bucket of event, only
+// when both have one left.
+func (l *rateLimiter) allow(event *types.EventIssue, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var buckets []*tokenBucket
+
+	if l.fingerprint.EventsPerSecond > 0 {
+		key := strings.Join(event.Fingerprint, "\x00")
+		bucket, ok := l.fingerprintBuckets[key]
+		if !ok {
+			l.evictFullBuckets(now)
+			bucket = newTokenBucket(l.fingerprint, now)
+			l.fingerprintBuckets[key] = bucket
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	level := types.EventLevel(event.Level)
+	if limit, ok := l.levels[level]; ok {
+		bucket, ok := l.levelBuckets[level]
+		if !ok {
+			bucket = newTokenBucket(limit, now)
+			l.levelBuckets[level] = bucket
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	for _, bucket := range buckets {
+		bucket.refill(now)
+		if bucket.tokens < 1 {
+			return false
+		}
+	}
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return true
+}
+
+func (l *rateLimiter) evictFullBuckets(now time.Time) {
+	if len(l.fingerprintBuckets) < maxRateLimitBuckets {
+		return
+	}
+	for key, bucket := range l.fingerprintBuckets {
+		bucket.refill(now)
+		if bucket.full() {
+			delete(l.fingerprintBuckets, key)
+		}
+	}
+}
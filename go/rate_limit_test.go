@@ -0,0 +1,89 @@
+package errortracker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("should limit events per fingerprint", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.FingerprintRateLimit = types.RateLimit{EventsPerSecond: 0.001, Burst: 2}
+		})
+
+		for i := 0; i < 5; i++ {
+			client.Error(errors.New("a"), types.LevelError, nil, Fingerprint("a"))
+		}
+		client.Error(errors.New("b"), types.LevelError, nil, Fingerprint("b"))
+
+		if queueLength(client) != 3 {
+			t.Errorf("expected 2 events of a and 1 of b, got %d", queueLength(client))
+		}
+		if stats := client.Stats(); stats.EventsRateLimited != 3 {
+			t.Errorf("expected 3 rate limited events, got %d", stats.EventsRateLimited)
+		}
+	})
+
+	t.Run("should not take tokens for dropped events", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.FingerprintRateLimit = types.RateLimit{EventsPerSecond: 0.001, Burst: 1}
+			config.BeforeSend = func(event *types.EventIssue, hint types.Hint) *types.EventIssue {
+				if event.Title == "ignored" {
+					return nil
+				}
+				return event
+			}
+		})
+
+		client.Error(errors.New("ignored"), types.LevelError, nil, Fingerprint("a"))
+		client.Error(errors.New("reported"), types.LevelError, nil, Fingerprint("a"))
+
+		if queueLength(client) != 1 {
+			t.Errorf("expected 1 queued event, got %d", queueLength(client))
+		}
+		if stats := client.Stats(); stats.EventsRateLimited != 0 || stats.EventsDropped != 1 {
+			t.Errorf("expected 1 dropped and no rate limited events, got %+v", stats)
+		}
+	})
+
+	t.Run("should limit events per level", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.LevelRateLimits = map[types.EventLevel]types.RateLimit{
+				types.LevelInfo: {EventsPerSecond: 0.001, Burst: 1},
+			}
+		})
+
+		client.Event("first", types.LevelInfo, nil)
+		client.Event("second", types.LevelInfo, nil)
+		client.Event("error", types.LevelError, nil)
+
+		if queueLength(client) != 2 {
+			t.Errorf("expected 2 queued events, got %d", queueLength(client))
+		}
+	})
+
+	t.Run("should refill over time", func(t *testing.T) {
+		limiter := newRateLimiter(types.RateLimit{EventsPerSecond: 1}, nil)
+		event := &types.EventIssue{Fingerprint: []string{"a"}}
+		now := time.Now()
+
+		if !limiter.allow(event, now) {
+			t.Fatal("expected first event to pass")
+		}
+		if limiter.allow(event, now) {
+			t.Error("expected bucket to be empty")
+		}
+		if !limiter.allow(event, now.Add(time.Second)) {
+			t.Error("expected bucket to refill after a second")
+		}
+	})
+
+	t.Run("should be disabled without limits", func(t *testing.T) {
+		if limiter := newRateLimiter(types.RateLimit{}, map[types.EventLevel]types.RateLimit{types.LevelInfo: {}}); limiter != nil {
+			t.Error("expected no rate limiter")
+		}
+	})
+}
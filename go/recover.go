@@ -0,0 +1,100 @@
+package errortracker
+
+import (
+	"context"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+// Recover reports a panic as an unhandled FATAL event and stops it. It must be
+// deferred directly: defer client.Recover().
+func (c *ErrorTrackerClient) Recover() {
+	if value := recover(); value != nil {
+		c.capturePanic(context.Background(), c.hub.Scope(), value)
+	}
+}
+
+func (c *ErrorTrackerClient) RecoverCtx(ctx context.Context) {
+	if value := recover(); value != nil {
+		c.capturePanic(ctx, c.scopeFor(ctx), value)
+	}
+}
+
+// RecoverAndRepanic reports a panic and panics again with the same value,
+// flushing the queue first when FlushOnPanic is enabled.
+func (c *ErrorTrackerClient) RecoverAndRepanic() {
+	if value := recover(); value != nil {
+		c.capturePanic(context.Background(), c.hub.Scope(), value)
+		c.flushOnPanic()
+		panic(value)
+	}
+}
+
+// Go runs f in a new goroutine with a copy of the current scope and reports
+// any panic it raises instead of crashing the process.
+func (c *ErrorTrackerClient) Go(f func()) {
+	scope := c.hub.Scope().Clone()
+	go func() {
+		defer func() {
+			if value := recover(); value != nil {
+				c.capturePanic(context.Background(), scope, value)
+			}
+		}()
+		f()
+	}()
+}
+
+// CapturePanic reports a value already obtained from recover(). Integrations
+// that need to decide themselves whether to re-panic use it.
+func (c *ErrorTrackerClient) CapturePanic(ctx context.Context, value any) *ErrorTrackerClient {
+	c.capturePanic(ctx, c.scopeFor(ctx), value)
+	return c
+}
+
+func (c *ErrorTrackerClient) capturePanic(ctx context.Context, scope *core.Scope, value any) {
+	if !c.isEnabled {
+		return
+	}
+
+	hint := types.Hint{PanicValue: value}
+	if err, ok := value.(error); ok {
+		hint.OriginalError = err
+	}
+
+	event := c.eventBuilder.BuildPanic(ctx, value, scope)
+	c.send(ctx, scope, event, hint)
+}
+
+func (c *ErrorTrackerClient) flushOnPanic() {
+	if c.config.FlushOnPanic {
+		c.ForceFlush()
+	}
+}
+
+func Recover() {
+	if value := recover(); value != nil {
+		if client, err := Get(); err == nil {
+			client.capturePanic(context.Background(), client.hub.Scope(), value)
+		}
+	}
+}
+
+func RecoverAndRepanic() {
+	if value := recover(); value != nil {
+		if client, err := Get(); err == nil {
+			client.capturePanic(context.Background(), client.hub.Scope(), value)
+			client.flushOnPanic()
+		}
+		panic(value)
+	}
+}
+
+func Go(f func()) error {
+	client, err := Get()
+	if err != nil {
+		return err
+	}
+	client.Go(f)
+	return nil
+}
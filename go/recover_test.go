@@ -0,0 +1,190 @@
+package errortracker
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+type paymentError struct {
+	code int
+}
+
+func (e *paymentError) Error() string {
+	return "payment failed"
+}
+
+func panicWithString() {
+	panic("boom")
+}
+
+func TestRecover(t *testing.T) {
+	t.Run("should report panic as unhandled fatal event", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		func() {
+			defer client.Recover()
+			panicWithString()
+		}()
+
+		event := lastQueuedEvent(t, client)
+		if event.Level != string(types.LevelFatal) {
+			t.Errorf("expected level FATAL, got %s", event.Level)
+		}
+		if event.Event.Name != "string" {
+			t.Errorf("expected name string, got %s", event.Event.Name)
+		}
+		if event.Event.Message != "boom" {
+			t.Errorf("expected message boom, got %s", event.Event.Message)
+		}
+		if event.Event.Mechanism == nil || event.Event.Mechanism.Type != "panic" || event.Event.Mechanism.Handled {
+			t.Errorf("expected unhandled panic mechanism, got %+v", event.Event.Mechanism)
+		}
+		if !strings.Contains(event.Context.Culprit, "panicWithString") {
+			t.Errorf("expected culprit to be the panicking function, got %s", event.Context.Culprit)
+		}
+		if !strings.Contains(event.Event.Stack, "panicWithString") {
+			t.Errorf("expected stack of the panicking goroutine, got %s", event.Event.Stack)
+		}
+	})
+
+	t.Run("should keep the panic value type", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		func() {
+			defer client.Recover()
+			panic(&paymentError{code: 402})
+		}()
+
+		event := lastQueuedEvent(t, client)
+		if event.Event.Name != "*errortracker.paymentError" {
+			t.Errorf("expected name *errortracker.paymentError, got %s", event.Event.Name)
+		}
+		if event.Event.Message != "payment failed" {
+			t.Errorf("expected message payment failed, got %s", event.Event.Message)
+		}
+	})
+
+	t.Run("should use the root cause of panicking errors", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		func() {
+			defer client.Recover()
+			panic(fmt.Errorf("checkout: %w", &paymentError{code: 402}))
+		}()
+
+		event := lastQueuedEvent(t, client)
+		if event.Event.Name != "*errortracker.paymentError" {
+			t.Errorf("expected name *errortracker.paymentError, got %s", event.Event.Name)
+		}
+	})
+
+	t.Run("should do nothing without panic", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		func() {
+			defer client.Recover()
+		}()
+
+		client.queueMu.Lock()
+		queueLen := len(client.eventQueue)
+		client.queueMu.Unlock()
+
+		if queueLen != 0 {
+			t.Errorf("expected queue length to be 0, got %d", queueLen)
+		}
+	})
+}
+
+func TestRecoverAndRepanic(t *testing.T) {
+	t.Run("should report and panic again", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		var repanicked any
+		func() {
+			defer func() {
+				repanicked = recover()
+			}()
+			defer client.RecoverAndRepanic()
+			panicWithString()
+		}()
+
+		if repanicked != "boom" {
+			t.Errorf("expected original panic value, got %v", repanicked)
+		}
+
+		event := lastQueuedEvent(t, client)
+		if event.Event.Mechanism == nil || event.Event.Mechanism.Type != "panic" {
+			t.Errorf("expected panic mechanism, got %+v", event.Event.Mechanism)
+		}
+	})
+
+	t.Run("should flush before panicking again", func(t *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		config := &types.ClientConfig{
+			WebhookURL:    server.URL,
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			FlushOnPanic:  true,
+		}
+
+		client, _ := NewClient(config)
+
+		func() {
+			defer func() {
+				recover()
+			}()
+			defer client.RecoverAndRepanic()
+			panicWithString()
+		}()
+
+		if atomic.LoadInt32(&requests) != 1 {
+			t.Errorf("expected 1 request before re-panic, got %d", requests)
+		}
+	})
+}
+
+func TestGo(t *testing.T) {
+	t.Run("should report panics of the goroutine", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		done := make(chan struct{})
+		client.Go(func() {
+			defer close(done)
+			panicWithString()
+		})
+		<-done
+
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			client.queueMu.Lock()
+			queueLen := len(client.eventQueue)
+			client.queueMu.Unlock()
+			if queueLen > 0 {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		event := lastQueuedEvent(t, client)
+		if event.Level != string(types.LevelFatal) {
+			t.Errorf("expected level FATAL, got %s", event.Level)
+		}
+		if !strings.Contains(event.Context.Culprit, "panicWithString") {
+			t.Errorf("expected culprit to be the panicking function, got %s", event.Context.Culprit)
+		}
+	})
+}
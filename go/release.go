@@ -0,0 +1,34 @@
+package errortracker
+
+import (
+	"runtime/debug"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+// Release is used as the version when ClientConfig.Version is empty, before
+// falling back to the build info. Set it at link time:
+//
+//	go build -ldflags "-X github.com/royaltics/tracker-go.Release=v1.4.2"
+var Release string
+
+// detectBuild fills an empty config.Version and returns the build context
+// and, when requested, the module list.
+func detectBuild(config *types.ClientConfig) (*types.BuildContext, map[string]string) {
+	info, _ := debug.ReadBuildInfo()
+	build := core.BuildContext(info)
+
+	if config.Version == "" {
+		config.Version = Release
+	}
+	if config.Version == "" {
+		config.Version = core.Release(build)
+	}
+
+	var modules map[string]string
+	if config.AttachModules {
+		modules = core.Modules(info)
+	}
+	return build, modules
+}
@@ -0,0 +1,114 @@
+package errortracker
+
+import (
+	"errors"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestRelease(t *testing.T) {
+	t.Run("should derive the release from build info", func(t *testing.T) {
+		info := &debug.BuildInfo{
+			GoVersion: "go1.22.1",
+			Main:      debug.Module{Path: "github.com/acme/shop", Version: "(devel)"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "4f2c9a7e1b3d5f6a7b8c9d0e1f2a3b4c5d6e7f80"},
+				{Key: "vcs.time", Value: "2024-03-01T12:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}
+
+		build := core.BuildContext(info)
+		if build.Module != "github.com/acme/shop" || build.Version != "" || !build.Modified {
+			t.Errorf("unexpected build context %+v", build)
+		}
+		if build.Time != "2024-03-01T12:00:00Z" || build.GoVersion != "go1.22.1" {
+			t.Errorf("expected vcs time and go version, got %+v", build)
+		}
+		if release := core.Release(build); release != "4f2c9a7e1b3d+dirty" {
+			t.Errorf("expected short dirty revision, got %s", release)
+		}
+
+		info.Main.Version = "v1.4.2"
+		if release := core.Release(core.BuildContext(info)); release != "v1.4.2" {
+			t.Errorf("expected module version, got %s", release)
+		}
+	})
+
+	t.Run("should list modules", func(t *testing.T) {
+		info := &debug.BuildInfo{
+			Deps: []*debug.Module{
+				{Path: "github.com/google/uuid", Version: "v1.6.0"},
+				{Path: "example.com/fork", Version: "v1.0.0", Replace: &debug.Module{Path: "../fork"}},
+			},
+		}
+
+		modules := core.Modules(info)
+		if modules["github.com/google/uuid"] != "v1.6.0" || modules["example.com/fork"] != "=> ../fork" {
+			t.Errorf("unexpected modules %v", modules)
+		}
+	})
+
+	t.Run("should prefer the configured version and the linker override", func(t *testing.T) {
+		defer func(release string) { Release = release }(Release)
+
+		Release = "v9.9.9"
+		client := newContextTestClient(t)
+		client.Error(errors.New("boom"), types.LevelError, nil)
+		if version := lastQueuedEvent(t, client).Context.Version; version != "v9.9.9" {
+			t.Errorf("expected linker release, got %s", version)
+		}
+
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Version:       "2.0.0",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+		}
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		client.Error(errors.New("boom"), types.LevelError, nil)
+		if version := lastQueuedEvent(t, client).Context.Version; version != "2.0.0" {
+			t.Errorf("expected configured version, got %s", version)
+		}
+	})
+
+	t.Run("should attach build and modules context", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+			AttachModules: true,
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if event.Context.Build == nil || event.Context.Build.Module != "github.com/royaltics/tracker-go" {
+			t.Errorf("expected build context, got %+v", event.Context.Build)
+		}
+		if event.Context.Modules["github.com/google/uuid"] == "" {
+			t.Errorf("expected uuid module, got %v", event.Context.Modules)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package errortracker
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+var allocSink [][]byte
+
+func TestRuntimeContext(t *testing.T) {
+	t.Run("should describe the runtime", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		rc := lastQueuedEvent(t, client).Context.Runtime
+		if rc == nil {
+			t.Fatal("expected runtime context")
+		}
+		if rc.GoVersion != runtime.Version() || rc.GOARCH != runtime.GOARCH || rc.PID != os.Getpid() {
+			t.Errorf("unexpected runtime context %+v", rc)
+		}
+		if rc.NumGoroutine < 1 || rc.GOMAXPROCS < 1 || rc.NumCPU < 1 || rc.UptimeSeconds <= 0 {
+			t.Errorf("expected live process values, got %+v", rc)
+		}
+		if rc.Memory == nil || rc.Memory.HeapAlloc == 0 || rc.Memory.Sys == 0 {
+			t.Errorf("expected memory stats, got %+v", rc.Memory)
+		}
+	})
+
+	t.Run("should reuse memory stats except for fatal events", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(errors.New("first"), types.LevelError, nil)
+		first := lastQueuedEvent(t, client).Context.Runtime.Memory
+
+		allocSink = append(allocSink, make([]byte, 1<<20))
+		client.Error(errors.New("second"), types.LevelError, nil)
+		second := lastQueuedEvent(t, client).Context.Runtime.Memory
+
+		if first.TotalAlloc != second.TotalAlloc {
+			t.Errorf("expected cached memory stats, got %d and %d", first.TotalAlloc, second.TotalAlloc)
+		}
+
+		func() {
+			defer client.Recover()
+			panicWithString()
+		}()
+		fatal := lastQueuedEvent(t, client).Context.Runtime.Memory
+		if fatal.TotalAlloc <= first.TotalAlloc {
+			t.Errorf("expected fresh memory stats for a panic, got %d after %d", fatal.TotalAlloc, first.TotalAlloc)
+		}
+	})
+
+	t.Run("should leave memory stats out when disabled", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:     "https://api.example.com/webhook",
+			LicenseID:      "test-license",
+			LicenseDevice:  "test-device",
+			Enabled:        true,
+			MaxRetries:     3,
+			Timeout:        10 * time.Second,
+			FlushInterval:  5 * time.Second,
+			MaxQueueSize:   50,
+			MemStatsMaxAge: -1,
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		rc := lastQueuedEvent(t, client).Context.Runtime
+		if rc == nil || rc.Memory != nil {
+			t.Errorf("expected runtime context without memory stats, got %+v", rc)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package errortracker
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+// sample decides whether event is kept and records the applied rate on it.
+func (c *ErrorTrackerClient) sample(event *types.EventIssue) bool {
+	rate := c.sampleRate(event)
+	event.SampleRate = rate
+
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+
+	if c.config.DeterministicSampling && len(event.Fingerprint) > 0 {
+		return fingerprintRatio(event.Fingerprint) < rate
+	}
+	return rand.Float64() < rate
+}
+
+func (c *ErrorTrackerClient) sampleRate(event *types.EventIssue) float64 {
+	if c.config.Sampler != nil {
+		if rate, ok := c.runSampler(event); ok {
+			return clampRate(rate)
+		}
+	}
+	if rate, ok := c.config.LevelSampleRates[types.EventLevel(event.Level)]; ok {
+		return rate
+	}
+	return c.config.SampleRate
+}
+
+// runSampler isolates panics of the user sampler, falling back to the
+// configured rates.
+func (c *ErrorTrackerClient) runSampler(event *types.EventIssue) (rate float64, ok bool) {
+	defer func() {
+		if recover() != nil {
+			c.stats.processorPanics.Add(1)
+			ok = false
+		}
+	}()
+	return c.config.Sampler(event), true
+}
+
+func clampRate(rate float64) float64 {
+	if math.IsNaN(rate) || rate < 0 {
+		return 0
+	}
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+// fingerprintRatio maps a fingerprint to a stable value in [0, 1).
+func fingerprintRatio(fingerprint []string) float64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(strings.Join(fingerprint, "\x00")))
+	return float64(hash.Sum64()>>11) / (1 << 53)
+}
@@ -0,0 +1,148 @@
+package errortracker
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func newSamplingTestClient(t *testing.T, configure func(config *types.ClientConfig)) *ErrorTrackerClient {
+	t.Helper()
+
+	config := &types.ClientConfig{
+		WebhookURL:    "https://api.example.com/webhook",
+		LicenseID:     "test-license",
+		LicenseDevice: "test-device",
+		Enabled:       true,
+		MaxRetries:    3,
+		Timeout:       10 * time.Second,
+		FlushInterval: 5 * time.Second,
+		MaxQueueSize:  1000,
+	}
+	configure(config)
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return client
+}
+
+func TestSampling(t *testing.T) {
+	t.Run("should keep every event by default", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		if event := lastQueuedEvent(t, client); event.SampleRate != 1 {
+			t.Errorf("expected sample rate 1, got %v", event.SampleRate)
+		}
+	})
+
+	t.Run("should apply per-level rates", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.SampleRate = 0.5
+			config.LevelSampleRates = map[types.EventLevel]float64{
+				types.LevelDebug: 0,
+				types.LevelFatal: 1,
+			}
+		})
+
+		for i := 0; i < 20; i++ {
+			client.Event("noise", types.LevelDebug, nil)
+		}
+		client.Event("fatal", types.LevelFatal, nil)
+
+		if queueLength(client) != 1 {
+			t.Fatalf("expected only the fatal event, got %d", queueLength(client))
+		}
+		if event := lastQueuedEvent(t, client); event.SampleRate != 1 {
+			t.Errorf("expected sample rate 1, got %v", event.SampleRate)
+		}
+		if stats := client.Stats(); stats.EventsSampled != 20 {
+			t.Errorf("expected 20 sampled events, got %d", stats.EventsSampled)
+		}
+	})
+
+	t.Run("should prefer the sampler and record its rate", func(t *testing.T) {
+		// Just above the fingerprint ratio, so the event is always kept.
+		rate := (fingerprintRatio([]string{"checkout"}) + 1) / 2
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.DeterministicSampling = true
+			config.LevelSampleRates = map[types.EventLevel]float64{types.LevelError: 0}
+			config.Sampler = func(event *types.EventIssue) float64 {
+				if event.Title == "checkout" {
+					return rate
+				}
+				return 0
+			}
+		})
+
+		client.Event("checkout", types.LevelError, nil, Fingerprint("checkout"))
+		client.Event("health", types.LevelError, nil)
+
+		if queueLength(client) != 1 {
+			t.Fatalf("expected only the checkout event, got %d", queueLength(client))
+		}
+		event := lastQueuedEvent(t, client)
+		if event.Title != "checkout" || event.SampleRate != rate {
+			t.Errorf("expected checkout with rate %v, got %s with %v", rate, event.Title, event.SampleRate)
+		}
+	})
+
+	t.Run("should fall back when the sampler panics", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.Sampler = func(event *types.EventIssue) float64 {
+				panic("sampler bug")
+			}
+		})
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		if event := lastQueuedEvent(t, client); event.SampleRate != 1 {
+			t.Errorf("expected fallback rate 1, got %v", event.SampleRate)
+		}
+	})
+
+	t.Run("should decide deterministically per fingerprint", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.SampleRate = 0.5
+			config.DeterministicSampling = true
+		})
+
+		kept := 0
+		for group := 0; group < 50; group++ {
+			before := queueLength(client)
+			for i := 0; i < 5; i++ {
+				client.Error(errors.New("boom"), types.LevelError, nil, Fingerprint(fmt.Sprintf("group-%d", group)))
+			}
+			switch queueLength(client) - before {
+			case 0:
+			case 5:
+				kept++
+			default:
+				t.Fatalf("expected group %d to be kept or dropped as a whole", group)
+			}
+		}
+
+		if kept == 0 || kept == 50 {
+			t.Errorf("expected some groups to be sampled out, kept %d of 50", kept)
+		}
+	})
+
+	t.Run("should reject invalid rates", func(t *testing.T) {
+		configs := []*types.ClientConfig{
+			{WebhookURL: "https://api.example.com", LicenseID: "id", LicenseDevice: "device", SampleRate: 1.5},
+			{WebhookURL: "https://api.example.com", LicenseID: "id", LicenseDevice: "device", LevelSampleRates: map[types.EventLevel]float64{types.LevelInfo: -1}},
+		}
+
+		for _, config := range configs {
+			if _, err := NewClient(config); err == nil {
+				t.Errorf("expected error for %+v", config)
+			}
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package errortracker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+// SignalOptions configures HandleSignals.
+type SignalOptions struct {
+	// Signals start the shutdown. Defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+	// FlushTimeout bounds flushing every instance. Defaults to 5s.
+	FlushTimeout time.Duration
+	// RecordShutdown captures an INFO event naming the signal with every
+	// instance before flushing.
+	RecordShutdown bool
+	// OnShutdown is called once every instance is shut down, with the first
+	// flush error. Without it the signal is raised again, so the process
+	// exits the way it would have without the handler.
+	OnShutdown func(sig os.Signal, err error)
+	// ReportGoroutineDumps reports the stacks of all goroutines with every
+	// instance on SIGQUIT. The process keeps running instead of printing
+	// them and exiting.
+	ReportGoroutineDumps bool
+}
+
+// HandleSignals shuts every instance down when a shutdown signal arrives,
+// flushing their queued events first. It returns right away and stops
+// listening when ctx is done. A second signal during the shutdown is not
+// caught, so it ends the process at once.
+func HandleSignals(ctx context.Context, opts SignalOptions) {
+	signals := opts.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if opts.FlushTimeout <= 0 {
+		opts.FlushTimeout = 5 * time.Second
+	}
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, signals...)
+	var quit chan os.Signal
+	if opts.ReportGoroutineDumps {
+		quit = make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGQUIT)
+	}
+
+	go func() {
+		defer signal.Stop(shutdown)
+		if quit != nil {
+			defer signal.Stop(quit)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig := <-quit:
+				for _, client := range registeredClients() {
+					client.captureGoroutineDump(ctx, sig)
+				}
+			case sig := <-shutdown:
+				signal.Stop(shutdown)
+				err := shutdownOnSignal(ctx, sig, opts)
+				if opts.OnShutdown != nil {
+					opts.OnShutdown(sig, err)
+					return
+				}
+				raise(sig)
+				return
+			}
+		}
+	}()
+}
+
+func shutdownOnSignal(ctx context.Context, sig os.Signal, opts SignalOptions) error {
+	if opts.RecordShutdown {
+		for _, client := range registeredClients() {
+			client.CaptureEvent(ctx, "Shutting down", types.LevelInfo, map[string]any{"signal": sig.String()})
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Shutdown()
+	}()
+
+	timer := time.NewTimer(opts.FlushTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("shutdown timed out after %v", opts.FlushTimeout)
+	}
+}
+
+// raise sends sig to the process again with the default behavior restored.
+func raise(sig os.Signal) {
+	signal.Reset(sig)
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Signal(sig)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+func registeredClients() []*ErrorTrackerClient {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	clients := make([]*ErrorTrackerClient, 0, len(instances)+1)
+	if defaultInstance != nil {
+		clients = append(clients, defaultInstance)
+	}
+	for _, client := range instances {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+func (c *ErrorTrackerClient) captureGoroutineDump(ctx context.Context, sig os.Signal) {
+	if !c.isEnabled {
+		return
+	}
+
+	scope := c.scopeFor(ctx)
+	event := c.eventBuilder.BuildGoroutineDump(ctx, "Goroutine dump", types.LevelInfo, map[string]any{"signal": sig.String()}, scope)
+	c.send(ctx, scope, event, types.Hint{})
+}
@@ -0,0 +1,136 @@
+//go:build unix
+
+package errortracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func newSignalTestServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func createSignalTestClient(t *testing.T, url string, name ...string) *ErrorTrackerClient {
+	t.Helper()
+
+	config := &types.ClientConfig{
+		WebhookURL:    url,
+		LicenseID:     "test-license",
+		LicenseDevice: "test-device",
+		Enabled:       true,
+		MaxRetries:    1,
+		Timeout:       time.Second,
+		FlushInterval: 5 * time.Second,
+		MaxQueueSize:  50,
+	}
+
+	client, err := Create(config, name...)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() { Shutdown() })
+	return client
+}
+
+func TestHandleSignals(t *testing.T) {
+	t.Run("should flush every instance and call OnShutdown", func(t *testing.T) {
+		server, requests := newSignalTestServer(t)
+		createSignalTestClient(t, server.URL)
+		createSignalTestClient(t, server.URL, "billing")
+
+		type shutdown struct {
+			sig os.Signal
+			err error
+		}
+		done := make(chan shutdown, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		HandleSignals(ctx, SignalOptions{
+			RecordShutdown: true,
+			OnShutdown: func(sig os.Signal, err error) {
+				done <- shutdown{sig, err}
+			},
+		})
+
+		syscall.Kill(os.Getpid(), syscall.SIGTERM)
+
+		select {
+		case result := <-done:
+			if result.sig != syscall.SIGTERM || result.err != nil {
+				t.Errorf("expected SIGTERM without error, got %v %v", result.sig, result.err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected OnShutdown to be called")
+		}
+		if got := atomic.LoadInt32(requests); got != 2 {
+			t.Errorf("expected a shutdown event from each instance, got %d requests", got)
+		}
+		if Has() || Has("billing") {
+			t.Error("expected the instances to be shut down")
+		}
+	})
+
+	t.Run("should report goroutine dumps on SIGQUIT", func(t *testing.T) {
+		server, _ := newSignalTestServer(t)
+		client := createSignalTestClient(t, server.URL)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		HandleSignals(ctx, SignalOptions{ReportGoroutineDumps: true})
+
+		syscall.Kill(os.Getpid(), syscall.SIGQUIT)
+
+		deadline := time.Now().Add(time.Second)
+		for queueLength(client) == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		event := lastQueuedEvent(t, client)
+		if event.Title != "Goroutine dump" || event.Level != string(types.LevelInfo) || event.Event.Name != "goroutine dump" {
+			t.Errorf("unexpected event %s %s %s", event.Title, event.Level, event.Event.Name)
+		}
+		if hasTagKey(event.Context.Tags, "error") {
+			t.Errorf("expected no error type tag, got %v", event.Context.Tags)
+		}
+		if event.Goroutines == nil || event.Goroutines.Total == 0 {
+			t.Errorf("expected goroutines, got %+v", event.Goroutines)
+		}
+		if event.Context.Extra["signal"] != "quit" {
+			t.Errorf("expected the signal, got %v", event.Context.Extra)
+		}
+	})
+
+	t.Run("should raise the signal again without OnShutdown", func(t *testing.T) {
+		server, requests := newSignalTestServer(t)
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCrashHelper$")
+		cmd.Env = append(os.Environ(), crashHelperEnv+"=signals", webhookEnv+"="+server.URL)
+		cmd.Run()
+
+		status, _ := cmd.ProcessState.Sys().(syscall.WaitStatus)
+		if !status.Signaled() || status.Signal() != syscall.SIGTERM {
+			t.Errorf("expected the helper to die of SIGTERM, got %v", cmd.ProcessState)
+		}
+		if got := atomic.LoadInt32(requests); got != 1 {
+			t.Errorf("expected the shutdown event, got %d requests", got)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package trackerslog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type Options struct {
+	// Client defaults to the default instance, resolved on every record.
+	Client *errortracker.ErrorTrackerClient
+	// EventLevel is the minimum level reported as an event. Defaults to
+	// slog.LevelError.
+	EventLevel slog.Leveler
+	// BreadcrumbLevel is the minimum level recorded as a breadcrumb. Defaults
+	// to slog.LevelInfo.
+	BreadcrumbLevel slog.Leveler
+}
+
+// Handler forwards records to the tracker and then to the wrapped handler, so
+// normal logging output is unaffected.
+type Handler struct {
+	next    slog.Handler
+	options Options
+	attrs   []slog.Attr
+	groups  []string
+}
+
+func NewHandler(next slog.Handler, options Options) *Handler {
+	if options.EventLevel == nil {
+		options.EventLevel = slog.LevelError
+	}
+	if options.BreadcrumbLevel == nil {
+		options.BreadcrumbLevel = slog.LevelInfo
+	}
+	return &Handler{
+		next:    next,
+		options: options,
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.options.BreadcrumbLevel.Level() {
+		return true
+	}
+	return h.next != nil && h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= h.options.BreadcrumbLevel.Level() {
+		h.track(ctx, record)
+	}
+
+	if h.next != nil && h.next.Enabled(ctx, record.Level) {
+		return h.next.Handle(ctx, record)
+	}
+	return nil
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := h.clone()
+	prefix := strings.Join(h.groups, ".")
+	for _, attr := range attrs {
+		if prefix != "" {
+			attr = slog.Group(prefix, attr)
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	if h.next != nil {
+		clone.next = h.next.WithAttrs(attrs)
+	}
+	return clone
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := h.clone()
+	clone.groups = append(clone.groups, name)
+	if h.next != nil {
+		clone.next = h.next.WithGroup(name)
+	}
+	return clone
+}
+
+func (h *Handler) clone() *Handler {
+	return &Handler{
+		next:    h.next,
+		options: h.options,
+		attrs:   append([]slog.Attr(nil), h.attrs...),
+		groups:  append([]string(nil), h.groups...),
+	}
+}
+
+func (h *Handler) track(ctx context.Context, record slog.Record) {
+	hub := h.hub(ctx)
+	if hub == nil {
+		return
+	}
+
+	values := make(map[string]slog.Value)
+	var err error
+	collect := func(prefix string, attr slog.Attr) {
+		if found := flattenAttr(values, prefix, attr); err == nil && found != nil {
+			err = found
+		}
+	}
+
+	for _, attr := range h.attrs {
+		collect("", attr)
+	}
+	prefix := strings.Join(h.groups, ".")
+	record.Attrs(func(attr slog.Attr) bool {
+		collect(prefix, attr)
+		return true
+	})
+
+	level := EventLevel(record.Level)
+
+	if record.Level < h.options.EventLevel.Level() {
+		data := make(map[string]string, len(values))
+		for key, value := range values {
+			data[key] = value.String()
+		}
+		hub.AddBreadcrumb(types.Breadcrumb{
+			Category:  "log",
+			Message:   record.Message,
+			Level:     level,
+			Data:      data,
+			Timestamp: record.Time,
+		})
+		return
+	}
+
+	extra := make(map[string]any, len(values)+1)
+	for key, value := range values {
+		extra[key] = value.Any()
+	}
+	if err != nil {
+		extra["message"] = record.Message
+		hub.CaptureError(ctx, err, level, extra)
+		return
+	}
+	hub.CaptureEvent(ctx, record.Message, level, extra)
+}
+
+func (h *Handler) hub(ctx context.Context) *errortracker.Hub {
+	if hub := errortracker.HubFromContext(ctx); hub != nil {
+		return hub
+	}
+	if h.options.Client != nil {
+		return h.options.Client.Hub()
+	}
+	if client, err := errortracker.Get(); err == nil {
+		return client.Hub()
+	}
+	return nil
+}
+
+// flattenAttr writes attr into values using dotted keys for groups and
+// returns the first error value it finds.
+func flattenAttr(values map[string]slog.Value, prefix string, attr slog.Attr) error {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return nil
+	}
+
+	key := attr.Key
+	if prefix != "" {
+		if key == "" {
+			key = prefix
+		} else {
+			key = prefix + "." + key
+		}
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		var found error
+		for _, child := range attr.Value.Group() {
+			if err := flattenAttr(values, key, child); found == nil && err != nil {
+				found = err
+			}
+		}
+		return found
+	}
+
+	values[key] = attr.Value
+	if err, ok := attr.Value.Any().(error); ok && attr.Value.Kind() == slog.KindAny {
+		return err
+	}
+	return nil
+}
+
+func EventLevel(level slog.Level) types.EventLevel {
+	switch {
+	case level > slog.LevelError:
+		return types.LevelFatal
+	case level >= slog.LevelError:
+		return types.LevelError
+	case level >= slog.LevelWarn:
+		return types.LevelWarning
+	case level >= slog.LevelInfo:
+		return types.LevelInfo
+	default:
+		return types.LevelDebug
+	}
+}
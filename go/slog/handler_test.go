@@ -0,0 +1,163 @@
+package trackerslog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/internal/trackertest"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type orderError struct {
+	id string
+}
+
+func (e *orderError) Error() string {
+	return fmt.Sprintf("order %s failed", e.id)
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("should report error records as events", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		logger := slog.New(NewHandler(slog.NewTextHandler(io.Discard, nil), Options{Client: client}))
+
+		logger.With("service", "billing").
+			WithGroup("order").
+			Error("checkout failed", "id", "42", "err", &orderError{id: "42"})
+
+		recorded := events()
+		if len(recorded) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recorded))
+		}
+		event := recorded[0]
+		if event.Level != string(types.LevelError) {
+			t.Errorf("expected level %s, got %s", types.LevelError, event.Level)
+		}
+		if event.Event.Name != "*trackerslog.orderError" || event.Event.Message != "order 42 failed" {
+			t.Errorf("unexpected error %+v", event.Event)
+		}
+		if event.Context.Extra["message"] != "checkout failed" {
+			t.Errorf("expected log message in extra, got %v", event.Context.Extra["message"])
+		}
+		if event.Context.Extra["service"] != "billing" || event.Context.Extra["order.id"] != "42" {
+			t.Errorf("expected flattened attributes, got %v", event.Context.Extra)
+		}
+		if !strings.Contains(event.Context.Culprit, "slog.TestHandler") {
+			t.Errorf("expected culprit in the test, got %s", event.Context.Culprit)
+		}
+	})
+
+	t.Run("should report error records without error attribute by message", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		logger := slog.New(NewHandler(nil, Options{Client: client}))
+
+		logger.Error("disk almost full", "free_mb", 12)
+
+		recorded := events()
+		if len(recorded) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(recorded))
+		}
+		if recorded[0].Title != "disk almost full" {
+			t.Errorf("expected title from message, got %s", recorded[0].Title)
+		}
+		if recorded[0].Context.Extra["free_mb"] != float64(12) {
+			t.Errorf("expected attribute in extra, got %v", recorded[0].Context.Extra)
+		}
+	})
+
+	t.Run("should group message records by message", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		logger := slog.New(NewHandler(nil, Options{Client: client}))
+
+		for _, message := range []string{"payment declined", "database down", "order 17 failed", "order 42 failed"} {
+			logger.Error(message)
+		}
+
+		fingerprints := make(map[string]string)
+		for _, event := range events() {
+			fingerprints[event.Title] = strings.Join(event.Fingerprint, ",")
+		}
+		if len(fingerprints) != 4 {
+			t.Fatalf("expected 4 events, got %v", fingerprints)
+		}
+		if fingerprints["payment declined"] == fingerprints["database down"] {
+			t.Error("expected different messages to get different fingerprints")
+		}
+		if fingerprints["order 17 failed"] != fingerprints["order 42 failed"] {
+			t.Error("expected messages that differ in numbers to share a fingerprint")
+		}
+	})
+
+	t.Run("should record lower levels as breadcrumbs", func(t *testing.T) {
+		client, events := trackertest.NewRecordingClient(t)
+		hub := client.Hub().Clone()
+		ctx := errortracker.ContextWithHub(context.Background(), hub)
+
+		var out bytes.Buffer
+		logger := slog.New(NewHandler(slog.NewTextHandler(&out, nil), Options{Client: client}))
+
+		logger.With("service", "billing").InfoContext(ctx, "order created", "id", "123")
+		logger.DebugContext(ctx, "ignored")
+
+		if len(events()) != 0 {
+			t.Error("expected no events for info records")
+		}
+
+		breadcrumbs := hub.Scope().Breadcrumbs()
+		if len(breadcrumbs) != 1 {
+			t.Fatalf("expected 1 breadcrumb, got %d", len(breadcrumbs))
+		}
+		breadcrumb := breadcrumbs[0]
+		if breadcrumb.Category != "log" || breadcrumb.Message != "order created" {
+			t.Errorf("unexpected breadcrumb %+v", breadcrumb)
+		}
+		if breadcrumb.Data["service"] != "billing" || breadcrumb.Data["id"] != "123" {
+			t.Errorf("expected attributes in breadcrumb data, got %v", breadcrumb.Data)
+		}
+		if !strings.Contains(out.String(), "order created") {
+			t.Errorf("expected wrapped handler to log, got %q", out.String())
+		}
+	})
+
+	t.Run("should honour the wrapped handler level", func(t *testing.T) {
+		client, _ := trackertest.NewRecordingClient(t)
+
+		var out bytes.Buffer
+		next := slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelWarn})
+		logger := slog.New(NewHandler(next, Options{Client: client}))
+
+		logger.Info("below wrapped level")
+		logger.Warn("visible")
+
+		if strings.Contains(out.String(), "below wrapped level") {
+			t.Error("expected info record not to reach the wrapped handler")
+		}
+		if !strings.Contains(out.String(), "visible") {
+			t.Errorf("expected warning to be logged, got %q", out.String())
+		}
+	})
+}
+
+func TestEventLevel(t *testing.T) {
+	t.Run("should map slog levels", func(t *testing.T) {
+		cases := map[slog.Level]types.EventLevel{
+			slog.LevelDebug:     types.LevelDebug,
+			slog.LevelInfo:      types.LevelInfo,
+			slog.LevelWarn:      types.LevelWarning,
+			slog.LevelError:     types.LevelError,
+			slog.LevelError + 4: types.LevelFatal,
+		}
+
+		for level, want := range cases {
+			if got := EventLevel(level); got != want {
+				t.Errorf("expected %s for %s, got %s", want, level, got)
+			}
+		}
+	})
+}
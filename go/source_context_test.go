@@ -0,0 +1,86 @@
+package errortracker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func testFrame(t *testing.T, event types.EventIssue) types.StackFrame {
+	t.Helper()
+
+	for _, frame := range event.Event.Frames {
+		if strings.HasPrefix(frame.Function, "TestSourceContext") {
+			return frame
+		}
+	}
+	t.Fatalf("expected a frame in the test, got %+v", event.Event.Frames)
+	return types.StackFrame{}
+}
+
+func TestSourceContext(t *testing.T) {
+	t.Run("should attach lines around in-app frames", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.SourceContextLines = 2
+		})
+
+		// before the capture
+		client.Error(errors.New("source context"), types.LevelError, nil)
+		// after the capture
+
+		frame := testFrame(t, lastQueuedEvent(t, client))
+		if !strings.Contains(frame.ContextLine, `client.Error(errors.New("source context")`) {
+			t.Errorf("expected the capture line, got %q", frame.ContextLine)
+		}
+		if len(frame.PreContext) != 2 || !strings.Contains(frame.PreContext[1], "before the capture") {
+			t.Errorf("expected 2 lines before, got %q", frame.PreContext)
+		}
+		if len(frame.PostContext) != 2 || !strings.Contains(frame.PostContext[0], "after the capture") {
+			t.Errorf("expected 2 lines after, got %q", frame.PostContext)
+		}
+
+		for _, frame := range lastQueuedEvent(t, client).Event.Frames {
+			if !frame.InApp && frame.ContextLine != "" {
+				t.Errorf("expected no context for %s.%s", frame.Package, frame.Function)
+			}
+		}
+	})
+
+	t.Run("should be disabled by default", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(errors.New("no context"), types.LevelError, nil)
+
+		if frame := testFrame(t, lastQueuedEvent(t, client)); frame.ContextLine != "" || frame.PreContext != nil {
+			t.Errorf("expected no source context, got %+v", frame)
+		}
+	})
+
+	t.Run("should skip files larger than the cache", func(t *testing.T) {
+		client := newSamplingTestClient(t, func(config *types.ClientConfig) {
+			config.SourceContextLines = 2
+			config.SourceCacheBytes = 64
+		})
+
+		client.Error(errors.New("too large"), types.LevelError, nil)
+
+		if frame := testFrame(t, lastQueuedEvent(t, client)); frame.ContextLine != "" {
+			t.Errorf("expected no source context, got %q", frame.ContextLine)
+		}
+	})
+
+	t.Run("should reject too many context lines", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:         "https://api.example.com/webhook",
+			LicenseID:          "test-license",
+			LicenseDevice:      "test-device",
+			SourceContextLines: 51,
+		}
+
+		if _, err := NewClient(config); err == nil {
+			t.Error("expected error for sourceContextLines above 50")
+		}
+	})
+}
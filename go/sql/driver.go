@@ -0,0 +1,286 @@
+package trackersql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/types"
+)
+
+// Register wraps d and registers it under name so it can be used with
+// sql.Open. Every statement executed through it is recorded as a breadcrumb.
+func Register(name string, d driver.Driver) {
+	sql.Register(name, Wrap(d))
+}
+
+func Wrap(d driver.Driver) driver.Driver {
+	return &wrappedDriver{parent: d}
+}
+
+// WrapConnector is the sql.OpenDB counterpart of Wrap.
+func WrapConnector(c driver.Connector) driver.Connector {
+	return &wrappedConnector{parent: c, driver: Wrap(c.Driver())}
+}
+
+type wrappedDriver struct {
+	parent driver.Driver
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.parent.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{parent: conn}, nil
+}
+
+type wrappedConnector struct {
+	parent driver.Connector
+	driver driver.Driver
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.parent.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{parent: conn}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type wrappedConn struct {
+	parent driver.Conn
+}
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.parent.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.parent.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedStmt{parent: stmt, query: query}, nil
+}
+
+func (c *wrappedConn) Close() error {
+	return c.parent.Close()
+}
+
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.parent.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	// Like database/sql, refuse options the driver cannot apply.
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+
+	tx, err := c.parent.Begin()
+	if err == nil && ctx.Done() != nil {
+		select {
+		case <-ctx.Done():
+			tx.Rollback()
+			return nil, ctx.Err()
+		default:
+		}
+	}
+	return tx, err
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.parent.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	recordQuery(ctx, query, start, err)
+	return result, err
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.parent.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	recordQuery(ctx, query, start, err)
+	return rows, err
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.parent.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.parent.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if validator, ok := c.parent.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *wrappedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.parent.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	_, execer := c.parent.(driver.ExecerContext)
+	_, queryer := c.parent.(driver.QueryerContext)
+	if !execer && !queryer {
+		// ExecContext and QueryContext skip to a prepared statement, whose
+		// column converters then check the original arguments.
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+type wrappedStmt struct {
+	parent driver.Stmt
+	query  string
+}
+
+func (s *wrappedStmt) Close() error {
+	return s.parent.Close()
+}
+
+func (s *wrappedStmt) NumInput() int {
+	return s.parent.NumInput()
+}
+
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	start := time.Now()
+	result, err := s.parent.Exec(args)
+	recordQuery(context.Background(), s.query, start, err)
+	return result, err
+}
+
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.parent.Query(args)
+	recordQuery(context.Background(), s.query, start, err)
+	return rows, err
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var (
+		result driver.Result
+		err    error
+	)
+
+	start := time.Now()
+	if execer, ok := s.parent.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err != nil {
+			return nil, err
+		}
+		result, err = s.parent.Exec(values)
+	}
+	recordQuery(ctx, s.query, start, err)
+	return result, err
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var (
+		rows driver.Rows
+		err  error
+	)
+
+	start := time.Now()
+	if queryer, ok := s.parent.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValuesToValues(args); err != nil {
+			return nil, err
+		}
+		rows, err = s.parent.Query(values)
+	}
+	recordQuery(ctx, s.query, start, err)
+	return rows, err
+}
+
+func (s *wrappedStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.parent.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (s *wrappedStmt) ColumnConverter(index int) driver.ValueConverter {
+	if converter, ok := s.parent.(driver.ColumnConverter); ok {
+		return converter.ColumnConverter(index)
+	}
+	return driver.DefaultParameterConverter
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("trackersql: driver does not support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+func recordQuery(ctx context.Context, query string, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	breadcrumb := types.Breadcrumb{
+		Category: "query",
+		Message:  query,
+		Level:    types.LevelInfo,
+		Data: map[string]string{
+			"duration_ms": strconv.FormatInt(time.Since(start).Milliseconds(), 10),
+		},
+	}
+	if err != nil {
+		breadcrumb.Level = types.LevelError
+		breadcrumb.Data["error"] = err.Error()
+	}
+
+	errortracker.AddBreadcrumbCtx(ctx, breadcrumb)
+}
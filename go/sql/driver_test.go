@@ -0,0 +1,247 @@
+package trackersql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	errortracker "github.com/royaltics/tracker-go"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type fakeDriver struct {
+	withContext bool
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	if d.withContext {
+		return &fakeContextConn{}, nil
+	}
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+type fakeTx struct{}
+
+func (tx *fakeTx) Commit() error {
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	return nil
+}
+
+type fakeContextConn struct {
+	fakeConn
+}
+
+func (c *fakeContextConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if query == "FAIL" {
+		return nil, errors.New("syntax error")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeContextConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+func (s *fakeStmt) ColumnConverter(index int) driver.ValueConverter {
+	return listConverter{}
+}
+
+// listConverter stores string lists as comma separated values.
+type listConverter struct{}
+
+func (listConverter) ConvertValue(value any) (driver.Value, error) {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ","), nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(value)
+}
+
+type fakeRows struct{}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+func newTestHub(t *testing.T) *errortracker.Hub {
+	t.Helper()
+
+	config := &types.ClientConfig{
+		WebhookURL:    "https://api.example.com/webhook",
+		LicenseID:     "test-license",
+		LicenseDevice: "test-device",
+		Enabled:       true,
+		MaxRetries:    3,
+		Timeout:       10 * time.Second,
+		FlushInterval: 5 * time.Second,
+		MaxQueueSize:  50,
+	}
+
+	client, err := errortracker.NewClient(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return client.Hub().Clone()
+}
+
+func TestDriver(t *testing.T) {
+	Register("trackersql-fake-context", &fakeDriver{withContext: true})
+	Register("trackersql-fake-legacy", &fakeDriver{})
+
+	t.Run("should record queries as breadcrumbs", func(t *testing.T) {
+		db, err := sql.Open("trackersql-fake-context", "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer db.Close()
+
+		hub := newTestHub(t)
+		ctx := errortracker.ContextWithHub(context.Background(), hub)
+
+		if _, err := db.ExecContext(ctx, "UPDATE orders SET paid = ?", true); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		rows, err := db.QueryContext(ctx, "SELECT id FROM orders")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		rows.Close()
+
+		breadcrumbs := hub.Scope().Breadcrumbs()
+		if len(breadcrumbs) != 2 {
+			t.Fatalf("expected 2 breadcrumbs, got %d", len(breadcrumbs))
+		}
+		if breadcrumbs[0].Category != "query" || breadcrumbs[0].Message != "UPDATE orders SET paid = ?" {
+			t.Errorf("unexpected breadcrumb %+v", breadcrumbs[0])
+		}
+		if breadcrumbs[1].Message != "SELECT id FROM orders" {
+			t.Errorf("unexpected breadcrumb %+v", breadcrumbs[1])
+		}
+	})
+
+	t.Run("should record failed queries as errors", func(t *testing.T) {
+		db, _ := sql.Open("trackersql-fake-context", "")
+		defer db.Close()
+
+		hub := newTestHub(t)
+		ctx := errortracker.ContextWithHub(context.Background(), hub)
+
+		if _, err := db.ExecContext(ctx, "FAIL"); err == nil {
+			t.Fatal("expected error")
+		}
+
+		breadcrumbs := hub.Scope().Breadcrumbs()
+		if len(breadcrumbs) != 1 {
+			t.Fatalf("expected 1 breadcrumb, got %d", len(breadcrumbs))
+		}
+		if breadcrumbs[0].Level != types.LevelError || breadcrumbs[0].Data["error"] != "syntax error" {
+			t.Errorf("unexpected breadcrumb %+v", breadcrumbs[0])
+		}
+	})
+
+	t.Run("should record statements of drivers without context support", func(t *testing.T) {
+		db, _ := sql.Open("trackersql-fake-legacy", "")
+		defer db.Close()
+
+		hub := newTestHub(t)
+		ctx := errortracker.ContextWithHub(context.Background(), hub)
+
+		if _, err := db.ExecContext(ctx, "DELETE FROM carts WHERE id = ?", 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		breadcrumbs := hub.Scope().Breadcrumbs()
+		if len(breadcrumbs) != 1 {
+			t.Fatalf("expected 1 breadcrumb, got %d", len(breadcrumbs))
+		}
+		if breadcrumbs[0].Message != "DELETE FROM carts WHERE id = ?" {
+			t.Errorf("unexpected breadcrumb %+v", breadcrumbs[0])
+		}
+	})
+
+	t.Run("should convert arguments with the column converter of the driver", func(t *testing.T) {
+		db, _ := sql.Open("trackersql-fake-legacy", "")
+		defer db.Close()
+
+		if _, err := db.Exec("INSERT INTO carts (tags) VALUES (?)", []string{"gift", "express"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		stmt, err := db.Prepare("INSERT INTO carts (tags) VALUES (?)")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer stmt.Close()
+		if _, err := stmt.Exec([]string{"gift"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("should refuse transaction options of drivers without context support", func(t *testing.T) {
+		db, _ := sql.Open("trackersql-fake-legacy", "")
+		defer db.Close()
+		ctx := context.Background()
+
+		if _, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}); err == nil {
+			t.Error("expected an isolation level error")
+		}
+		if _, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true}); err == nil {
+			t.Error("expected a read-only error")
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		tx.Rollback()
+	})
+}
@@ -0,0 +1,173 @@
+package errortracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/core"
+	"github.com/royaltics/tracker-go/types"
+)
+
+type tracedError struct {
+	pcs []uintptr
+}
+
+func (e *tracedError) Error() string {
+	return "traced failure"
+}
+
+func (e *tracedError) Callers() []uintptr {
+	return e.pcs
+}
+
+type stackFrame uintptr
+
+// brokenTraceError has a pkg/errors style StackTrace method that panics.
+type brokenTraceError struct{}
+
+func (e *brokenTraceError) Error() string {
+	return "broken trace"
+}
+
+func (e *brokenTraceError) StackTrace() []stackFrame {
+	panic("no trace")
+}
+
+func newTracedError() error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(1, pcs)
+	return &tracedError{pcs: pcs[:n]}
+}
+
+func TestStackFrames(t *testing.T) {
+	t.Run("should report frames from the capture site", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Event.Frames) == 0 {
+			t.Fatal("expected frames")
+		}
+
+		first := event.Event.Frames[0]
+		if !strings.HasPrefix(first.Function, "TestStackFrames") {
+			t.Errorf("expected first frame to be the test, got %s", first.Function)
+		}
+		if first.Package != "github.com/royaltics/tracker-go" {
+			t.Errorf("expected package github.com/royaltics/tracker-go, got %s", first.Package)
+		}
+		if first.File != "stacktrace_test.go" {
+			t.Errorf("expected relative file stacktrace_test.go, got %s", first.File)
+		}
+		if !strings.HasSuffix(first.AbsPath, "/stacktrace_test.go") || first.Line == 0 {
+			t.Errorf("expected absolute path and line, got %s:%d", first.AbsPath, first.Line)
+		}
+		if !first.InApp {
+			t.Error("expected test frame to be in app")
+		}
+
+		for _, frame := range event.Event.Frames {
+			if strings.HasSuffix(frame.AbsPath, "/client.go") || strings.Contains(frame.AbsPath, "/core/") {
+				t.Errorf("expected SDK frames to be stripped, got %s", frame.AbsPath)
+			}
+			if frame.Package == "testing" && frame.InApp {
+				t.Error("expected standard library frames not to be in app")
+			}
+		}
+
+		if event.Event.Stack == "" {
+			t.Error("expected text stack to be kept")
+		}
+	})
+
+	t.Run("should use the stack recorded by the error", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		err := newTracedError()
+		client.Error(err, types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Event.Frames) == 0 || event.Event.Frames[0].Function != "newTracedError" {
+			t.Errorf("expected frames from newTracedError, got %+v", event.Event.Frames)
+		}
+	})
+
+	t.Run("should ignore stack trace methods that panic", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		client.Error(fmt.Errorf("wrapped: %w", &brokenTraceError{}), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Event.Frames) == 0 || !strings.HasPrefix(event.Event.Frames[0].Function, "TestStackFrames") {
+			t.Errorf("expected frames from the capture site, got %+v", event.Event.Frames)
+		}
+	})
+
+	t.Run("should honour in-app prefixes", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+			InAppPrefixes: []string{"github.com/example/shop"},
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		for _, frame := range event.Event.Frames {
+			if frame.InApp {
+				t.Errorf("expected %s.%s not to be in app", frame.Package, frame.Function)
+			}
+		}
+	})
+
+	t.Run("should treat the main module as in app by default", func(t *testing.T) {
+		builder := core.NewEventBuilder("", "", "", "test-device").
+			SetBuild(&types.BuildContext{Module: "myservice"}, nil)
+		output := "panic: boom\n\ngoroutine 7 [running]:\n" +
+			"myservice/billing.charge(...)\n\t/src/myservice/billing/charge.go:12 +0x1d\n" +
+			"main.handle(...)\n\t/src/myservice/main.go:9 +0x1d\n" +
+			"net/http.HandlerFunc.ServeHTTP(...)\n\t/usr/local/go/src/net/http/server.go:2136 +0x29\n"
+
+		event, ok := builder.BuildCrash(context.Background(), []byte(output))
+		if !ok || len(event.Event.Frames) != 3 {
+			t.Fatalf("expected 3 frames, got %+v", event.Event.Frames)
+		}
+		for i, want := range []bool{true, true, false} {
+			frame := event.Event.Frames[i]
+			if frame.InApp != want {
+				t.Errorf("expected %s.%s in app to be %v", frame.Package, frame.Function, want)
+			}
+		}
+	})
+
+	t.Run("should start panic frames at the panicking function", func(t *testing.T) {
+		client := newContextTestClient(t)
+
+		func() {
+			defer client.Recover()
+			panicWithString()
+		}()
+
+		event := lastQueuedEvent(t, client)
+		if len(event.Event.Frames) == 0 || event.Event.Frames[0].Function != "panicWithString" {
+			t.Errorf("expected first frame panicWithString, got %+v", event.Event.Frames)
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package errortracker
+
+import (
+	"sync/atomic"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+type clientStats struct {
+	captured        atomic.Uint64
+	dropped         atomic.Uint64
+	sampled         atomic.Uint64
+	suppressed      atomic.Uint64
+	rateLimited     atomic.Uint64
+	processorPanics atomic.Uint64
+}
+
+func (c *ErrorTrackerClient) Stats() types.ClientStats {
+	return types.ClientStats{
+		EventsCaptured:    c.stats.captured.Load(),
+		EventsDropped:     c.stats.dropped.Load(),
+		EventsSampled:     c.stats.sampled.Load(),
+		EventsSuppressed:  c.stats.suppressed.Load(),
+		EventsRateLimited: c.stats.rateLimited.Load(),
+		ProcessorPanics:   c.stats.processorPanics.Load(),
+	}
+}
@@ -1,6 +1,7 @@
 package errortracker
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -50,21 +51,76 @@ func Get(name ...string) (*ErrorTrackerClient, error) {
 	return defaultInstance, nil
 }
 
-func Error(err error, level types.EventLevel, metadata map[string]string) error {
-	client, e := Get()
+func Error(err error, level types.EventLevel, metadata map[string]string, options ...CaptureOption) error {
+	return ErrorCtx(context.Background(), err, level, metadata, options...)
+}
+
+func ErrorCtx(ctx context.Context, err error, level types.EventLevel, metadata map[string]string, options ...CaptureOption) error {
+	client, e := clientFor(ctx)
 	if e != nil {
 		return e
 	}
-	client.Error(err, level, metadata)
+	client.ErrorCtx(ctx, err, level, metadata, options...)
+	return nil
+}
+
+func Event(title string, level types.EventLevel, metadata map[string]string, options ...CaptureOption) error {
+	return EventCtx(context.Background(), title, level, metadata, options...)
+}
+
+func EventCtx(ctx context.Context, title string, level types.EventLevel, metadata map[string]string, options ...CaptureOption) error {
+	client, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+	client.EventCtx(ctx, title, level, metadata, options...)
+	return nil
+}
+
+func CaptureError(ctx context.Context, err error, level types.EventLevel, data map[string]any, options ...CaptureOption) error {
+	client, e := clientFor(ctx)
+	if e != nil {
+		return e
+	}
+	client.CaptureError(ctx, err, level, data, options...)
+	return nil
+}
+
+func CaptureEvent(ctx context.Context, title string, level types.EventLevel, data map[string]any, options ...CaptureOption) error {
+	client, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+	client.CaptureEvent(ctx, title, level, data, options...)
 	return nil
 }
 
-func Event(title string, level types.EventLevel, metadata map[string]string) error {
+func AddBreadcrumb(breadcrumb types.Breadcrumb) error {
+	return AddBreadcrumbCtx(context.Background(), breadcrumb)
+}
+
+// AddBreadcrumbCtx records the breadcrumb on the hub carried by ctx, falling
+// back to the client in ctx and finally to the default instance.
+func AddBreadcrumbCtx(ctx context.Context, breadcrumb types.Breadcrumb) error {
+	if hub := HubFromContext(ctx); hub != nil {
+		hub.AddBreadcrumb(breadcrumb)
+		return nil
+	}
+
+	client, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+	client.AddBreadcrumb(breadcrumb)
+	return nil
+}
+
+func SetUser(user types.User) error {
 	client, err := Get()
 	if err != nil {
 		return err
 	}
-	client.Event(title, level, metadata)
+	client.SetUser(user)
 	return nil
 }
 
@@ -98,21 +154,23 @@ func Shutdown() error {
 	mu.Lock()
 	defer mu.Unlock()
 
+	var firstErr error
+
 	if defaultInstance != nil {
 		if err := defaultInstance.Shutdown(); err != nil {
-			return err
+			firstErr = err
 		}
 		defaultInstance = nil
 	}
 
 	for _, client := range instances {
-		if err := client.Shutdown(); err != nil {
-			return err
+		if err := client.Shutdown(); err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
 
 	instances = make(map[string]*ErrorTrackerClient)
-	return nil
+	return firstErr
 }
 
 func Has(name ...string) bool {
@@ -1,8 +1,10 @@
 package types
 
 import (
+	"context"
 	"errors"
 	"net/url"
+	"regexp"
 	"time"
 )
 
@@ -17,19 +19,109 @@ const (
 )
 
 type ClientConfig struct {
-	WebhookURL    string
-	LicenseID     string
-	LicenseName   string
-	LicenseDevice string
-	App           string
-	Version       string
-	Platform      string
-	Enabled       bool
-	MaxRetries    int
-	Timeout       time.Duration
-	FlushInterval time.Duration
-	MaxQueueSize  int
-	Headers       map[string]string
+	WebhookURL     string
+	LicenseID      string
+	LicenseName    string
+	LicenseDevice  string
+	App            string
+	Version        string
+	Platform       string
+	Enabled        bool
+	MaxRetries     int
+	Timeout        time.Duration
+	FlushInterval  time.Duration
+	MaxQueueSize   int
+	MaxBreadcrumbs int
+	FlushOnPanic   bool
+	InAppPrefixes  []string
+	MaxErrorDepth  int
+	Headers        map[string]string
+	// BeforeSend runs last on every event, after all event processors.
+	// Returning nil drops the event.
+	BeforeSend func(event *EventIssue, hint Hint) *EventIssue
+	// SampleRate is the share of events kept, between 0 and 1. Zero means 1.
+	SampleRate float64
+	// LevelSampleRates overrides SampleRate for the listed levels.
+	LevelSampleRates map[EventLevel]float64
+	// Sampler returns the rate of an event and takes priority over both maps.
+	Sampler func(event *EventIssue) float64
+	// DeterministicSampling derives the decision from the fingerprint, so all
+	// events of a group are either kept or dropped together.
+	DeterministicSampling bool
+	// DedupeWindow sends the first event of a fingerprint and counts its
+	// repeats for this long, then emits them once with the number of
+	// occurrences. Zero disables it.
+	DedupeWindow time.Duration
+	// FingerprintRateLimit and LevelRateLimits cap the events emitted per
+	// fingerprint and per level.
+	FingerprintRateLimit RateLimit
+	LevelRateLimits      map[EventLevel]RateLimit
+	// Scrubbing redacts secrets and personal data before events are sent.
+	// It is enabled by default.
+	Scrubbing ScrubOptions
+	// MetadataLimits bounds the metadata attached to events.
+	MetadataLimits MetadataLimits
+	// MemStatsMaxAge is how long memory stats in the runtime context are
+	// reused. Zero means 10s and a negative value leaves them out.
+	MemStatsMaxAge time.Duration
+	// AttachModules adds the module dependencies of the binary to events.
+	AttachModules bool
+	// SourceContextLines is the number of source lines read before and after
+	// the line of in-app frames, when the file is on disk. Zero disables it.
+	SourceContextLines int
+	// SourceCacheBytes caps the source files kept in memory. Defaults to 4 MiB.
+	SourceCacheBytes int
+	// AttachGoroutines adds the stacks of all goroutines to FATAL events.
+	AttachGoroutines bool
+	// MaxGoroutineDumpBytes caps the stack dump read for AttachGoroutines.
+	// Defaults to 1 MiB.
+	MaxGoroutineDumpBytes int
+	// CrashFile is where Start asks the runtime to write fatal crash output.
+	// The next NewClient reports a crash found there. Requires Go 1.23 or
+	// later; NewClient returns an error on older versions.
+	CrashFile string
+}
+
+// MetadataLimits caps nesting depth, entries per map, slice or struct, and
+// string length in bytes. Zero values use 6, 100 and 8192.
+type MetadataLimits struct {
+	MaxDepth        int
+	MaxItems        int
+	MaxStringLength int
+}
+
+// RateLimit configures a token bucket. Burst defaults to EventsPerSecond
+// rounded up, and a zero EventsPerSecond disables the limit.
+type RateLimit struct {
+	EventsPerSecond float64
+	Burst           int
+}
+
+type ScrubOptions struct {
+	Disabled bool
+	// DenyKeys are added to the default denylist. Keys match when they
+	// contain a denied word, ignoring case and "-", "_", "." separators.
+	// Denied words of up to 3 letters, such as "ssn", must be a whole word
+	// of the key: "user_ssn" matches but "classname" does not.
+	DenyKeys []string
+	// AllowKeys are never scrubbed. In AllowlistMode they are the only
+	// metadata keys whose values are kept.
+	AllowKeys     []string
+	AllowlistMode bool
+	// Rules are applied after the default JWT, bearer token, email, IP and
+	// card number rules.
+	Rules               []ScrubRule
+	DisableDefaultRules bool
+}
+
+// ScrubRule replaces every match of Pattern with Replacement, which may refer
+// to submatches and defaults to "[Filtered]". When Validate is set, only the
+// matches it accepts are replaced.
+type ScrubRule struct {
+	Name        string
+	Pattern     *regexp.Regexp
+	Replacement string
+	Validate    func(match string) bool
 }
 
 func (c *ClientConfig) Validate() error {
@@ -37,7 +129,8 @@ func (c *ClientConfig) Validate() error {
 		return errors.New("webhookURL is required")
 	}
 
-	if _, err := url.Parse(c.WebhookURL); err != nil {
+	parsed, err := url.Parse(c.WebhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
 		return errors.New("webhookURL must be a valid URL")
 	}
 
@@ -65,33 +158,225 @@ func (c *ClientConfig) Validate() error {
 		return errors.New("maxQueueSize must be at least 1")
 	}
 
+	if c.MaxBreadcrumbs > 1000 {
+		return errors.New("maxBreadcrumbs must be at most 1000")
+	}
+
+	if c.SourceContextLines < 0 || c.SourceContextLines > 50 {
+		return errors.New("sourceContextLines must be between 0 and 50")
+	}
+
+	if c.MaxErrorDepth < 0 || c.MaxErrorDepth > 100 {
+		return errors.New("maxErrorDepth must be between 0 and 100")
+	}
+
+	if c.DedupeWindow < 0 {
+		return errors.New("dedupeWindow must not be negative")
+	}
+
+	if c.FingerprintRateLimit.EventsPerSecond < 0 {
+		return errors.New("fingerprintRateLimit must not be negative")
+	}
+
+	for _, limit := range c.LevelRateLimits {
+		if limit.EventsPerSecond < 0 {
+			return errors.New("levelRateLimits must not be negative")
+		}
+	}
+
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return errors.New("sampleRate must be between 0 and 1")
+	}
+
+	for _, rate := range c.LevelSampleRates {
+		if rate < 0 || rate > 1 {
+			return errors.New("levelSampleRates must be between 0 and 1")
+		}
+	}
+
 	return nil
 }
 
+type User struct {
+	ID        string         `json:"id,omitempty"`
+	Username  string         `json:"username,omitempty"`
+	Email     string         `json:"email,omitempty"`
+	IPAddress string         `json:"ip_address,omitempty"`
+	Segment   string         `json:"segment,omitempty"`
+	Data      map[string]any `json:"data,omitempty"`
+}
+
 type EventContext struct {
 	Culprit  string            `json:"culprit"`
-	Extra    map[string]string `json:"extra,omitempty"`
+	Extra    map[string]any    `json:"extra,omitempty"`
 	Platform string            `json:"platform,omitempty"`
 	App      string            `json:"app,omitempty"`
 	Version  string            `json:"version,omitempty"`
 	Device   string            `json:"device,omitempty"`
 	Tags     []string          `json:"tags,omitempty"`
+	User     *User             `json:"user,omitempty"`
+	Runtime  *RuntimeContext   `json:"runtime,omitempty"`
+	Host     *HostContext      `json:"host,omitempty"`
+	OS       *OSContext        `json:"os,omitempty"`
+	Build    *BuildContext     `json:"build,omitempty"`
+	Modules  map[string]string `json:"modules,omitempty"`
+}
+
+// BuildContext identifies the binary that produced an event.
+type BuildContext struct {
+	Module    string `json:"module,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version,omitempty"`
+}
+
+type HostContext struct {
+	Hostname    string             `json:"hostname,omitempty"`
+	BootTime    string             `json:"boot_time,omitempty"`
+	ContainerID string             `json:"container_id,omitempty"`
+	Kubernetes  *KubernetesContext `json:"kubernetes,omitempty"`
+}
+
+type KubernetesContext struct {
+	PodName   string `json:"pod_name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	NodeName  string `json:"node_name,omitempty"`
+}
+
+type OSContext struct {
+	Name          string `json:"name"`
+	Version       string `json:"version,omitempty"`
+	PrettyName    string `json:"pretty_name,omitempty"`
+	KernelVersion string `json:"kernel_version,omitempty"`
+}
+
+// RuntimeContext describes the Go runtime of the process at capture time.
+type RuntimeContext struct {
+	GoVersion     string       `json:"go_version"`
+	GOARCH        string       `json:"goarch"`
+	GOMAXPROCS    int          `json:"gomaxprocs"`
+	NumCPU        int          `json:"num_cpu"`
+	NumGoroutine  int          `json:"num_goroutine"`
+	PID           int          `json:"pid"`
+	UptimeSeconds float64      `json:"uptime_seconds"`
+	Memory        *MemoryStats `json:"memory,omitempty"`
+}
+
+// MemoryStats holds selected runtime.MemStats fields, in bytes unless noted.
+type MemoryStats struct {
+	Alloc          uint64 `json:"alloc"`
+	TotalAlloc     uint64 `json:"total_alloc"`
+	Sys            uint64 `json:"sys"`
+	HeapAlloc      uint64 `json:"heap_alloc"`
+	HeapInuse      uint64 `json:"heap_inuse"`
+	HeapObjects    uint64 `json:"heap_objects"`
+	StackInuse     uint64 `json:"stack_inuse"`
+	NumGC          uint32 `json:"num_gc"`
+	GCPauseTotalNs uint64 `json:"gc_pause_total_ns"`
+	LastGCPauseNs  uint64 `json:"last_gc_pause_ns"`
+}
+
+type Mechanism struct {
+	Type    string `json:"type"`
+	Handled bool   `json:"handled"`
+}
+
+type StackFrame struct {
+	Function string `json:"function"`
+	Package  string `json:"package,omitempty"`
+	File     string `json:"file"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Line     int    `json:"line"`
+	InApp    bool   `json:"in_app"`
+
+	PreContext  []string `json:"pre_context,omitempty"`
+	ContextLine string   `json:"context_line,omitempty"`
+	PostContext []string `json:"post_context,omitempty"`
+}
+
+// Exception is one error of a wrapped error chain. Parent is the index of the
+// wrapping error in the list, or -1 for the outermost one.
+type Exception struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	Parent  int    `json:"parent"`
 }
 
 type SerializedError struct {
-	Name    string            `json:"name"`
-	Message string            `json:"message"`
-	Stack   string            `json:"stack,omitempty"`
-	Extra   map[string]string `json:"extra,omitempty"`
+	Name       string            `json:"name"`
+	Message    string            `json:"message"`
+	Stack      string            `json:"stack,omitempty"`
+	Frames     []StackFrame      `json:"frames,omitempty"`
+	Exceptions []Exception       `json:"exceptions,omitempty"`
+	Extra      map[string]string `json:"extra,omitempty"`
+	Mechanism  *Mechanism        `json:"mechanism,omitempty"`
+}
+
+// GoroutineDump lists the goroutines running when an event was captured.
+// Goroutines with the same state and stack are grouped.
+type GoroutineDump struct {
+	Total     int              `json:"total"`
+	Groups    []GoroutineGroup `json:"groups"`
+	Truncated bool             `json:"truncated,omitempty"`
+}
+
+// GoroutineGroup holds the first IDs of its goroutines and the longest wait
+// among them.
+type GoroutineGroup struct {
+	Count       int          `json:"count"`
+	IDs         []int64      `json:"ids"`
+	State       string       `json:"state"`
+	WaitMinutes int          `json:"wait_minutes,omitempty"`
+	Frames      []StackFrame `json:"frames"`
+	CreatedBy   *StackFrame  `json:"created_by,omitempty"`
+}
+
+type Breadcrumb struct {
+	Category  string            `json:"category,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Level     EventLevel        `json:"level,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
 }
 
 type EventIssue struct {
-	EventID   string          `json:"event_id"`
-	Title     string          `json:"title"`
-	Level     string          `json:"level"`
-	Event     SerializedError `json:"event"`
-	Context   EventContext    `json:"context"`
-	Timestamp string          `json:"timestamp"`
+	EventID     string          `json:"event_id"`
+	Title       string          `json:"title"`
+	Level       string          `json:"level"`
+	Event       SerializedError `json:"event"`
+	Context     EventContext    `json:"context"`
+	Fingerprint []string        `json:"fingerprint,omitempty"`
+	Breadcrumbs []Breadcrumb    `json:"breadcrumbs,omitempty"`
+	Goroutines  *GoroutineDump  `json:"goroutines,omitempty"`
+	SampleRate  float64         `json:"sample_rate,omitempty"`
+	Occurrences int             `json:"occurrences,omitempty"`
+	FirstSeen   string          `json:"first_seen,omitempty"`
+	LastSeen    string          `json:"last_seen,omitempty"`
+	Redactions  int             `json:"redactions,omitempty"`
+	Timestamp   string          `json:"timestamp"`
+}
+
+// Hint carries what an event was built from, for processors that need more
+// than the serialized event.
+type Hint struct {
+	Context       context.Context
+	OriginalError error
+	PanicValue    any
+}
+
+// EventProcessor inspects or mutates an event before it is queued. Returning
+// nil drops the event.
+type EventProcessor func(event *EventIssue, hint Hint) *EventIssue
+
+type ClientStats struct {
+	EventsCaptured    uint64 `json:"events_captured"`
+	EventsDropped     uint64 `json:"events_dropped"`
+	EventsSampled     uint64 `json:"events_sampled"`
+	EventsSuppressed  uint64 `json:"events_suppressed"`
+	EventsRateLimited uint64 `json:"events_rate_limited"`
+	ProcessorPanics   uint64 `json:"processor_panics"`
 }
 
 type TransportPayload struct {
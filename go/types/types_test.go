@@ -57,6 +57,24 @@ func TestClientConfigValidate(t *testing.T) {
 		}
 	})
 
+	t.Run("should return error for webhookURL without http scheme or host", func(t *testing.T) {
+		for _, webhookURL := range []string{"ftp://api.example.com/webhook", "https://", "/webhook"} {
+			config := &ClientConfig{
+				WebhookURL:    webhookURL,
+				LicenseID:     "test-license",
+				LicenseDevice: "test-device",
+				MaxRetries:    3,
+				Timeout:       10 * time.Second,
+				FlushInterval: 5 * time.Second,
+				MaxQueueSize:  50,
+			}
+
+			if err := config.Validate(); err == nil {
+				t.Errorf("expected error for webhookURL %q", webhookURL)
+			}
+		}
+	})
+
 	t.Run("should return error for empty licenseID", func(t *testing.T) {
 		config := &ClientConfig{
 			WebhookURL:    "https://api.example.com/webhook",
@@ -247,7 +265,7 @@ func TestEventContext(t *testing.T) {
 	t.Run("should create event context", func(t *testing.T) {
 		ctx := EventContext{
 			Culprit:  "main.go:42",
-			Extra:    map[string]string{"key": "value"},
+			Extra:    map[string]any{"key": "value"},
 			Platform: "linux",
 			App:      "test-app",
 			Version:  "1.0.0",
@@ -0,0 +1,109 @@
+package errortracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/royaltics/tracker-go/types"
+)
+
+func TestUser(t *testing.T) {
+	t.Run("should attach the client user", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.SetUser(types.User{
+			ID:       "u-42",
+			Username: "ada",
+			Segment:  "enterprise",
+			Data:     map[string]any{"seats": 25, "signup": time.Unix(0, 0)},
+		})
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		user := event.Context.User
+		if user == nil || user.ID != "u-42" || user.Username != "ada" || user.Segment != "enterprise" {
+			t.Fatalf("expected client user, got %+v", user)
+		}
+		if user.Data["seats"] != 25 || user.Data["signup"] != "1970-01-01T00:00:00Z" {
+			t.Errorf("expected sanitized user data, got %v", user.Data)
+		}
+
+		data, _ := json.Marshal(event)
+		if !strings.Contains(string(data), `"user":{"id":"u-42"`) {
+			t.Errorf("expected user object in payload, got %s", data)
+		}
+	})
+
+	t.Run("should prefer the context user", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.SetUser(types.User{ID: "service"})
+
+		ctx := WithUser(context.Background(), types.User{ID: "u-7"})
+		client.ErrorCtx(ctx, errors.New("boom"), types.LevelError, nil)
+
+		if user := lastQueuedEvent(t, client).Context.User; user == nil || user.ID != "u-7" {
+			t.Errorf("expected context user, got %+v", user)
+		}
+	})
+
+	t.Run("should scrub personal fields by default", func(t *testing.T) {
+		client := newContextTestClient(t)
+		client.SetUser(types.User{
+			ID:        "u-42",
+			Email:     "ada@example.com",
+			IPAddress: "203.0.113.7",
+			Data:      map[string]any{"password": "hunter2"},
+		})
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		event := lastQueuedEvent(t, client)
+		user := event.Context.User
+		if user.ID != "u-42" {
+			t.Errorf("expected id to be kept, got %s", user.ID)
+		}
+		if user.Email != "[Filtered]" || user.IPAddress != "[Filtered]" || user.Data["password"] != "[Filtered]" {
+			t.Errorf("expected personal fields to be filtered, got %+v", user)
+		}
+		if event.Redactions != 3 {
+			t.Errorf("expected 3 redactions, got %d", event.Redactions)
+		}
+	})
+
+	t.Run("should honour allowed and denied user fields", func(t *testing.T) {
+		config := &types.ClientConfig{
+			WebhookURL:    "https://api.example.com/webhook",
+			LicenseID:     "test-license",
+			LicenseDevice: "test-device",
+			Enabled:       true,
+			MaxRetries:    3,
+			Timeout:       10 * time.Second,
+			FlushInterval: 5 * time.Second,
+			MaxQueueSize:  50,
+			Scrubbing: types.ScrubOptions{
+				AllowKeys: []string{"email"},
+				DenyKeys:  []string{"username"},
+			},
+		}
+
+		client, err := NewClient(config)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		client.SetUser(types.User{ID: "u-42", Username: "ada", Email: "ada@example.com"})
+
+		client.Error(errors.New("boom"), types.LevelError, nil)
+
+		user := lastQueuedEvent(t, client).Context.User
+		if user.Email != "ada@example.com" {
+			t.Errorf("expected allowed email, got %s", user.Email)
+		}
+		if user.Username != "" {
+			t.Errorf("expected denied username to be dropped, got %s", user.Username)
+		}
+	})
+}